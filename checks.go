@@ -45,6 +45,26 @@ func WithCheckName(name string) CheckConfigModifierOption {
 	}
 }
 
+// WithCheckId lets you set a fixed Id on a CheckConfig instead of the
+// one derived from its Key
+func WithCheckId(id uuid.UUID) CheckConfigModifierOption {
+	return func() CheckConfigModifier {
+		return func(config *CheckConfig) {
+			config.Id = id
+		}
+	}
+}
+
+// WithCheckKey lets you modify the stable Key of a CheckConfig, its Id
+// is derived from it
+func WithCheckKey(key string) CheckConfigModifierOption {
+	return func() CheckConfigModifier {
+		return func(config *CheckConfig) {
+			config.Key = key
+		}
+	}
+}
+
 // WithCheckStatus lets you modify the Enabled flag of a CheckConfig
 func WithCheckStatus(enabled bool) CheckConfigModifierOption {
 	return func() CheckConfigModifier {
@@ -54,13 +74,91 @@ func WithCheckStatus(enabled bool) CheckConfigModifierOption {
 	}
 }
 
+// WithCheckCriticality lets you modify how much a failure of a
+// CheckConfig matters
+func WithCheckCriticality(criticality Criticality) CheckConfigModifierOption {
+	return func() CheckConfigModifier {
+		return func(config *CheckConfig) {
+			config.Criticality = criticality
+		}
+	}
+}
+
+// WithCheckProbes lets you modify which probe handlers evaluate a
+// CheckConfig
+func WithCheckProbes(probes ...Probe) CheckConfigModifierOption {
+	return func() CheckConfigModifier {
+		return func(config *CheckConfig) {
+			config.Probes = probes
+		}
+	}
+}
+
+// WithCheckTags lets you add tags to a CheckConfig
+func WithCheckTags(tags ...string) CheckConfigModifierOption {
+	return func() CheckConfigModifier {
+		return func(config *CheckConfig) {
+			config.Tags = append(config.Tags, tags...)
+		}
+	}
+}
+
+// WithCheckTimeout lets you modify the deadline of a single run of a
+// CheckConfig
+func WithCheckTimeout(timeout time.Duration) CheckConfigModifierOption {
+	return func() CheckConfigModifier {
+		return func(config *CheckConfig) {
+			config.Timeout = timeout
+		}
+	}
+}
+
+// WithCheckRetry lets you modify how a failing CheckConfig is retried
+// within a single run
+func WithCheckRetry(policy RetryPolicy) CheckConfigModifierOption {
+	return func() CheckConfigModifier {
+		return func(config *CheckConfig) {
+			config.Retry = policy
+		}
+	}
+}
+
+// WithCheckThresholds lets you modify how many consecutive failures flip
+// a CheckConfig to failing and how many consecutive successes flip it
+// back to healthy
+func WithCheckThresholds(failures, successes int) CheckConfigModifierOption {
+	return func() CheckConfigModifier {
+		return func(config *CheckConfig) {
+			config.FailureThreshold = failures
+			config.SuccessThreshold = successes
+		}
+	}
+}
+
+// WithCheckInterval lets you modify how often a CheckConfig runs once
+// the Engine is started
+func WithCheckInterval(interval time.Duration) CheckConfigModifierOption {
+	return func() CheckConfigModifier {
+		return func(config *CheckConfig) {
+			config.Interval = interval
+		}
+	}
+}
+
+// WithCheckJitter lets you add a random delay of up to jitter to every
+// interval of a CheckConfig
+func WithCheckJitter(jitter time.Duration) CheckConfigModifierOption {
+	return func() CheckConfigModifier {
+		return func(config *CheckConfig) {
+			config.Jitter = jitter
+		}
+	}
+}
+
 // WithCheckPostgresConnection creates a check initializer which creates a CheckConfig for
 // checking postgres connections
 func WithCheckPostgresConnection(pool *pgxpool.Pool, options ...CheckConfigModifierOption) CheckInit {
-
-	handlerFunc := func() (bool, string) {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+	handlerFunc := func(ctx context.Context) (bool, string) {
 		err := pool.Ping(ctx)
 		if err != nil {
 			return false, "Postgres connection failed: " + err.Error()
@@ -68,7 +166,6 @@ func WithCheckPostgresConnection(pool *pgxpool.Pool, options ...CheckConfigModif
 		return true, "Postgres connection successful"
 	}
 	config := CheckConfig{
-		Id:          uuid.New(),
 		Type:        CheckTypePostgresConnection,
 		Name:        "Postgres Connection",
 		Enabled:     true,
@@ -85,9 +182,7 @@ func WithCheckPostgresConnection(pool *pgxpool.Pool, options ...CheckConfigModif
 // WithCheckMongoConnection creates a check initializer which creates a CheckConfig for
 // checking mongo connections
 func WithCheckMongoConnection(client *mongo.Client, options ...CheckConfigModifierOption) CheckInit {
-	handlerFunc := func() (bool, string) {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+	handlerFunc := func(ctx context.Context) (bool, string) {
 		err := client.Ping(ctx, nil)
 		if err != nil {
 			return false, "MongoDB connection failed: " + err.Error()
@@ -96,7 +191,6 @@ func WithCheckMongoConnection(client *mongo.Client, options ...CheckConfigModifi
 	}
 
 	config := CheckConfig{
-		Id:          uuid.New(),
 		Type:        CheckTypeMongoConnection,
 		Name:        "Mongo Connection",
 		Enabled:     true,
@@ -113,18 +207,17 @@ func WithCheckMongoConnection(client *mongo.Client, options ...CheckConfigModifi
 // WithCheckSupabaseDBConnection creates a check initializer which creates a CheckConfig for
 // checking supabase database connections
 func WithCheckSupabaseDBConnection(projectRef, secretToken string, options ...CheckConfigModifierOption) CheckInit {
-	handlerFunc := func() (bool, string) {
+	handlerFunc := func(ctx context.Context) (bool, string) {
 		url := fmt.Sprintf("https://api.supabase.com/v1/projects/%s/health?services=db", projectRef)
 
-		req, err := http.NewRequest("GET", url, nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return false, fmt.Sprintf("Failed to create request: %v", err)
 		}
 
 		req.Header.Set("Authorization", "Bearer "+secretToken)
 
-		client := &http.Client{Timeout: 10 * time.Second}
-		resp, err := client.Do(req)
+		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
 			return false, fmt.Sprintf("Failed to send request: %v", err)
 		}
@@ -161,10 +254,10 @@ func WithCheckSupabaseDBConnection(projectRef, secretToken string, options ...Ch
 	}
 
 	config := CheckConfig{
-		Id:          uuid.New(),
 		Type:        CheckTypeSupabaseDBConnection,
 		Name:        "Supabase DB Connection",
 		Enabled:     true,
+		Timeout:     10 * time.Second,
 		HandlerFunc: handlerFunc,
 	}
 
@@ -178,8 +271,8 @@ func WithCheckSupabaseDBConnection(projectRef, secretToken string, options ...Ch
 // WithCheckCPUUsage creates a check initializer which creates a CheckConfig for
 // checking cpu usage
 func WithCheckCPUUsage(threshold float64, options ...CheckConfigModifierOption) CheckInit {
-	handlerFunc := func() (bool, string) {
-		percent, err := cpu.Percent(time.Second, false)
+	handlerFunc := func(ctx context.Context) (bool, string) {
+		percent, err := cpu.PercentWithContext(ctx, time.Second, false)
 		if err != nil {
 			return false, "Failed to get CPU usage: " + err.Error()
 		}
@@ -189,7 +282,6 @@ func WithCheckCPUUsage(threshold float64, options ...CheckConfigModifierOption)
 		return true, fmt.Sprintf("CPU usage is %.2f%%, which is below the threshold of %.2f%%", percent[0], threshold)
 	}
 	config := CheckConfig{
-		Id:          uuid.New(),
 		Type:        CheckTypeCPUUsage,
 		Name:        "CPU Usage",
 		Enabled:     true,
@@ -206,15 +298,14 @@ func WithCheckCPUUsage(threshold float64, options ...CheckConfigModifierOption)
 // WithCheckDatabaseConnection creates a check initializer which creates a CheckConfig for
 // checking database connections
 func WithCheckDatabaseConnection(db *sql.DB, options ...CheckConfigModifierOption) CheckInit {
-	handlerFunc := func() (bool, string) {
-		err := db.Ping()
+	handlerFunc := func(ctx context.Context) (bool, string) {
+		err := db.PingContext(ctx)
 		if err != nil {
 			return false, "Database connection failed"
 		}
 		return true, "Database connection successful"
 	}
 	config := CheckConfig{
-		Id:          uuid.New(),
 		Type:        CheckTypeDatabaseConnection,
 		Name:        "Check Database Connection",
 		Enabled:     true,
@@ -231,15 +322,14 @@ func WithCheckDatabaseConnection(db *sql.DB, options ...CheckConfigModifierOptio
 // WithCheckDatabaseQuery creates a check initializer which creates a CheckConfig for
 // checking database queries
 func WithCheckDatabaseQuery(db *sql.DB, options ...CheckConfigModifierOption) CheckInit {
-	handlerFunc := func() (bool, string) {
-		_, err := db.Exec("SELECT 1")
+	handlerFunc := func(ctx context.Context) (bool, string) {
+		_, err := db.ExecContext(ctx, "SELECT 1")
 		if err != nil {
 			return false, "Database query failed"
 		}
 		return true, "Database query successful"
 	}
 	config := CheckConfig{
-		Id:          uuid.New(),
 		Type:        CheckTypeDatabaseQuery,
 		Name:        "Check Database Query",
 		Enabled:     true,
@@ -256,15 +346,14 @@ func WithCheckDatabaseQuery(db *sql.DB, options ...CheckConfigModifierOption) Ch
 // WithCheckRedisConnection creates a check initializer which creates a CheckConfig for
 // checking redis connections
 func WithCheckRedisConnection(client *redis.Client, options ...CheckConfigModifierOption) CheckInit {
-	handlerFunc := func() (bool, string) {
-		_, err := client.Ping(context.Background()).Result()
+	handlerFunc := func(ctx context.Context) (bool, string) {
+		_, err := client.Ping(ctx).Result()
 		if err != nil {
 			return false, "Redis connection failed"
 		}
 		return true, "Redis connection successful"
 	}
 	config := CheckConfig{
-		Id:          uuid.New(),
 		Type:        CheckTypeRedisConnection,
 		Name:        "Check Redis Connection",
 		Enabled:     true,
@@ -281,7 +370,7 @@ func WithCheckRedisConnection(client *redis.Client, options ...CheckConfigModifi
 // WithCheckDiskSpace creates a check initializer which creates a CheckConfig for
 // checking disk space
 func WithCheckDiskSpace(threshold float64, options ...CheckConfigModifierOption) CheckInit {
-	handlerFunc := func() (bool, string) {
+	handlerFunc := func(context.Context) (bool, string) {
 		var stat syscall.Statfs_t
 		syscall.Statfs("/", &stat)
 		total := float64(stat.Blocks) * float64(stat.Bsize)
@@ -295,7 +384,6 @@ func WithCheckDiskSpace(threshold float64, options ...CheckConfigModifierOption)
 		return true, fmt.Sprintf("Disk usage is %.2f%%, which is below the threshold of %.2f%%", usagePercent, threshold)
 	}
 	config := CheckConfig{
-		Id:          uuid.New(),
 		Type:        CheckTypeDiskSpace,
 		Name:        "Check Disk Space",
 		Enabled:     true,
@@ -312,7 +400,7 @@ func WithCheckDiskSpace(threshold float64, options ...CheckConfigModifierOption)
 // WithCheckMemoryUsage creates a check initializer which creates a CheckConfig for
 // checking memory usage
 func WithCheckMemoryUsage(threshold float64, options ...CheckConfigModifierOption) CheckInit {
-	handlerFunc := func() (bool, string) {
+	handlerFunc := func(context.Context) (bool, string) {
 		var m runtime.MemStats
 		runtime.ReadMemStats(&m)
 		usagePercent := float64(m.Alloc) / float64(m.Sys) * 100
@@ -323,7 +411,6 @@ func WithCheckMemoryUsage(threshold float64, options ...CheckConfigModifierOptio
 		return true, fmt.Sprintf("Memory usage is %.2f%%, which is below the threshold of %.2f%%", usagePercent, threshold)
 	}
 	config := CheckConfig{
-		Id:          uuid.New(),
 		Type:        CheckTypeMemoryUsage,
 		Name:        "Check Memory Usage",
 		Enabled:     true,
@@ -1,18 +1,28 @@
 package main
 
 import (
+	"log"
 	"net/http"
 
 	"github.com/saintmalik/allgood"
 )
 
 func main() {
-	engine := allgood.NewEngine(
+	engine, err := allgood.NewEngineE(
 		allgood.WithCheckDiskSpace(90),
-		allgood.WithCheckMemoryUsage(90, allgood.WithCheckName("Jiggy PC Memory usage")),
-		allgood.WithCheckMemoryUsage(90),
 		allgood.WithCheckCPUUsage(90),
 	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	engine.SetDuplicatePolicy(allgood.DuplicateReject)
+	err = engine.AddChecks(
+		allgood.WithCheckMemoryUsage(90, allgood.WithCheckName("Jiggy PC Memory usage")),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	http.HandleFunc("/healthcheck", engine.HealthCheckHandler())
 	http.ListenAndServe(":8080", nil)
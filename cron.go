@@ -0,0 +1,167 @@
+package allgood
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed cron expression, every field holds the
+// values it matches.
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays map[int]bool
+	// anyDay and anyWeekday are set when the day of month or day of
+	// week field is *. when both are restricted a time matches when
+	// either does, like cron.
+	anyDay, anyWeekday bool
+}
+
+// cronDescriptors are the shorthands accepted in place of the five
+// fields.
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCron parses a standard five field cron expression, "minute hour
+// day-of-month month day-of-week". fields accept *, values, ranges,
+// lists and steps, e.g. "*/15 2-4 * * 1,3". day of week 7 is sunday
+// like 0.
+func parseCron(expr string) (cronSchedule, error) {
+	if descriptor, exists := cronDescriptors[strings.TrimSpace(expr)]; exists {
+		expr = descriptor
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return cronSchedule{}, fmt.Errorf("cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	var s cronSchedule
+	var err error
+	if s.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return cronSchedule{}, fmt.Errorf("cron expression %q: minute: %w", expr, err)
+	}
+	if s.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return cronSchedule{}, fmt.Errorf("cron expression %q: hour: %w", expr, err)
+	}
+	if s.days, err = parseCronField(fields[2], 1, 31); err != nil {
+		return cronSchedule{}, fmt.Errorf("cron expression %q: day of month: %w", expr, err)
+	}
+	if s.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return cronSchedule{}, fmt.Errorf("cron expression %q: month: %w", expr, err)
+	}
+	if s.weekdays, err = parseCronField(fields[4], 0, 7); err != nil {
+		return cronSchedule{}, fmt.Errorf("cron expression %q: day of week: %w", expr, err)
+	}
+	if s.weekdays[7] {
+		s.weekdays[0] = true
+	}
+	s.anyDay = strings.HasPrefix(fields[2], "*")
+	s.anyWeekday = strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// parseCronField parses a comma separated cron field of values between
+// min and max.
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	values := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if base, s, found := strings.Cut(part, "/"); found {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid step %q", s)
+			}
+			part, step = base, n
+		}
+
+		low, high := min, max
+		if part != "*" {
+			from, to, isRange := strings.Cut(part, "-")
+			var err error
+			if low, err = strconv.Atoi(from); err != nil {
+				return nil, fmt.Errorf("invalid value %q", from)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(to); err != nil {
+					return nil, fmt.Errorf("invalid value %q", to)
+				}
+			} else if step > 1 {
+				high = max
+			}
+		}
+		if low < min || high > max || low > high {
+			return nil, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+		for v := low; v <= high; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+// latest returns the latest minute at or before t matching the schedule
+// and reports whether it is after earliest. it walks back over the days
+// and only then over the hours and minutes of the matching ones.
+func (s cronSchedule) latest(t, earliest time.Time) (time.Time, bool) {
+	year, month, day := t.Date()
+	for d := 0; ; d++ {
+		date := time.Date(year, month, day-d, 0, 0, 0, 0, t.Location())
+		if !date.AddDate(0, 0, 1).After(earliest) {
+			return time.Time{}, false
+		}
+		if !s.matchesDay(date) {
+			continue
+		}
+		lastHour := 23
+		if d == 0 {
+			lastHour = t.Hour()
+		}
+		for hour := lastHour; hour >= 0; hour-- {
+			if !s.hours[hour] {
+				continue
+			}
+			lastMinute := 59
+			if d == 0 && hour == t.Hour() {
+				lastMinute = t.Minute()
+			}
+			for minute := lastMinute; minute >= 0; minute-- {
+				if !s.minutes[minute] {
+					continue
+				}
+				start := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, t.Location())
+				if start.After(t) {
+					// the wall clock time doesn't exist, it was
+					// skipped by a daylight saving change.
+					continue
+				}
+				return start, start.After(earliest)
+			}
+		}
+	}
+}
+
+// matchesDay reports whether the day of t matches the schedule.
+func (s cronSchedule) matchesDay(t time.Time) bool {
+	if !s.months[int(t.Month())] {
+		return false
+	}
+	day, weekday := s.days[t.Day()], s.weekdays[int(t.Weekday())]
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
@@ -1,18 +1,49 @@
 package allgood
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/saintmalik/allgood/internal/models"
-	"github.com/saintmalik/allgood/internal/notify"
 	"github.com/saintmalik/allgood/internal/views"
+	"github.com/saintmalik/allgood/notify"
 )
 
 const Version = "0.1.1"
 
+const (
+	// DefaultName is the name of an Engine when none is set with
+	// SetName.
+	DefaultName = "allgood"
+	// DefaultTimeout is the overall deadline applied to a run of
+	// all checks when none is set with SetTimeout.
+	DefaultTimeout = 10 * time.Second
+	// DefaultCheckTimeout is the deadline applied to a single check
+	// when none is set with WithCheckTimeout.
+	DefaultCheckTimeout = 5 * time.Second
+	// DefaultMaxConcurrency is the number of checks run at the same
+	// time when none is set with SetMaxConcurrency. zero means every
+	// enabled check runs at once.
+	DefaultMaxConcurrency = 0
+)
+
+const (
+	// StatusOK is the overall status when every check passes.
+	StatusOK = "ok"
+	// StatusDegraded is the overall status when only warning checks
+	// fail. the health check page still responds with 200.
+	StatusDegraded = "degraded"
+	// StatusError is the overall status when a critical check fails.
+	StatusError = "error"
+)
+
 var (
 	CheckTypePostgresConnection   CheckType   = "postgres"
 	CheckTypeMongoConnection      CheckType   = "mongo"
@@ -29,147 +60,638 @@ var (
 // CheckFunc is the function that performs checks
 type CheckFunc func() (bool, string)
 
+// CheckContextFunc is the function that performs checks. the context
+// is cancelled when the check times out or the request that triggered
+// it goes away.
+type CheckContextFunc func(ctx context.Context) (bool, string)
+
+// AdaptCheckFunc turns a CheckFunc into a CheckContextFunc. the
+// returned function ignores the context, the engine still stops
+// waiting for it once the check times out and leaves it running in the
+// background.
+func AdaptCheckFunc(fn CheckFunc) CheckContextFunc {
+	return func(context.Context) (bool, string) {
+		return fn()
+	}
+}
+
+// CheckStatus is the outcome reported by a CheckStatusFunc.
+type CheckStatus string
+
+const (
+	// CheckPass reports a healthy check.
+	CheckPass CheckStatus = "pass"
+	// CheckWarn reports a check that works but needs attention, e.g. a
+	// disk filling up. it counts as a success and makes the overall
+	// status degraded whatever the Criticality of the check.
+	CheckWarn CheckStatus = "warn"
+	// CheckFail reports a failed check, which is a warning or an error
+	// depending on the Criticality of the check.
+	CheckFail CheckStatus = "fail"
+)
+
+// CheckStatusFunc is the function that performs checks able to warn
+// rather than only pass or fail. the context is cancelled like the one
+// of a CheckContextFunc.
+type CheckStatusFunc func(ctx context.Context) (CheckStatus, string)
+
+// AdaptCheckContextFunc turns a CheckContextFunc into a
+// CheckStatusFunc, which never warns.
+func AdaptCheckContextFunc(fn CheckContextFunc) CheckStatusFunc {
+	return func(ctx context.Context) (CheckStatus, string) {
+		success, message := fn(ctx)
+		if success {
+			return CheckPass, message
+		}
+		return CheckFail, message
+	}
+}
+
 // CheckType is the category a check belongs to
 type CheckType string
 
+// Criticality is how much a failure of a check matters
+type Criticality string
+
+const (
+	// CriticalityCritical checks make the overall status an error
+	// when they fail. it is the default.
+	CriticalityCritical Criticality = "critical"
+	// CriticalityWarning checks make the overall status degraded
+	// when they fail.
+	CriticalityWarning Criticality = "warning"
+	// CriticalityInfo checks are reported but never change the
+	// overall status.
+	CriticalityInfo Criticality = "info"
+)
+
 // CheckConfig holds all the configurations required
 // to setup a check
 type CheckConfig struct {
-	// Id is the identifier of the created check
-	Id          uuid.UUID
+	// Id is the identifier of the created check. when it is not set
+	// the Engine derives it from Key, so it stays the same across
+	// restarts.
+	Id uuid.UUID
+	// Key is the stable, human readable identifier of the check. it
+	// defaults to the Type and Name of the check joined by a slash,
+	// e.g. "postgres/Postgres Connection".
+	Key string
 	// Type is the category the check to be be created belongs to
 	// an Engine may perform checks on e.g. multiple postgres connection
 	// checks
-	Type        CheckType
+	Type CheckType
 	// Name is the name of the check. a default name is set is a check
 	// initializer is used and may be overridden in the same check initializer.
-	Name        string
+	Name string
 	// Enabled is a flag to determine is the check should be executed.
-	Enabled     bool
+	Enabled bool
+	// Criticality is how much a failure of the check matters.
+	// CriticalityCritical is used when it is empty.
+	Criticality Criticality
+	// Probes are the probe handlers that evaluate the check.
+	// ProbeReadiness is used when it is empty.
+	Probes []Probe
+	// Tags are free form labels used to select checks, e.g. with
+	// ?tag=db on the health check page.
+	Tags []string
+	// Timeout is the deadline for a single run of the check, retries
+	// included. DefaultCheckTimeout is used when it is zero.
+	Timeout time.Duration
+	// Retry is how a failing check is retried within a single run.
+	Retry RetryPolicy
+	// FailureThreshold is the number of consecutive failures before a
+	// healthy check is reported as failing, SuccessThreshold the number
+	// of consecutive successes before a failing check is reported as
+	// healthy again. both default to 1.
+	FailureThreshold int
+	SuccessThreshold int
+	// Interval is how often the check runs once the Engine is started.
+	// DefaultCheckInterval is used when it is zero.
+	Interval time.Duration
+	// Jitter is the upper bound of a random delay added to every
+	// Interval so checks of the same kind don't all run at once.
+	Jitter time.Duration
 	// HandlerFunc is the function that performs the check
-	HandlerFunc CheckFunc
+	HandlerFunc CheckContextFunc
+	// StatusFunc performs the check instead of HandlerFunc when set,
+	// for checks that can warn.
+	StatusFunc CheckStatusFunc
 }
 
 // Engine
 type Engine struct {
-	checks   map[uuid.UUID]CheckConfig
-	notifier notify.Notifier
-	mu       sync.Mutex
+	name               string
+	version            string
+	releaseID          string
+	checks             map[uuid.UUID]CheckConfig
+	order              []uuid.UUID
+	results            map[uuid.UUID]models.Result
+	notifiers          []*routedNotifier
+	notifyErrorHandler NotifyErrorHandler
+	silences           []Silence
+	maintenance        []maintenanceWindow
+	timeout            time.Duration
+	maxConcurrency     int
+	duplicatePolicy    DuplicatePolicy
+	scheduler          *scheduler
+	startup            startupState
+	panicHandler       PanicHandler
+	telemetry          telemetry
+	watchers           map[chan struct{}]struct{}
+	mu                 sync.Mutex
 }
 
 // NewEngine creates a new Engine.
-// 
+//
 // it accepts variable check initializers used to create CheckConfig.
-// 
+// duplicates are handled following DuplicateKeepFirst and checks that
+// can't be registered are dropped, use NewEngineE to get their errors.
+//
 // # Example
-// 	import (
-// 	"github.com/saintmalik/allgood"
-// 	"net/http"
-// )
-// 	engine := NewEngine(allgood.WithCheckMemoryUsage(90))
-// 	http.HandleFunc("/healthcheck", engine.HealthCheckHandler())
-// 	http.ListenAndServe(":8080")
+//
+//	import (
+//		"github.com/saintmalik/allgood"
+//		"net/http"
+//	)
+//
+//	engine := NewEngine(allgood.WithCheckMemoryUsage(90))
+//	http.HandleFunc("/healthcheck", engine.HealthCheckHandler())
+//	http.ListenAndServe(":8080")
 func NewEngine(checkInitializers ...CheckInit) *Engine {
-	checks := make(map[uuid.UUID]CheckConfig, len(checkInitializers))
-	for _, initializer := range checkInitializers {
-		config := initializer()
-		// TODO: ignore adding check config for times in
-		// AvoidDuplicateFor it types already exist in the
-		// checks.
-		checks[config.Id] = config
-	}
-	return &Engine{
-		checks: checks,
-	}
+	e, _ := NewEngineE(checkInitializers...)
+	return e
 }
 
-
+// NewEngineE creates a new Engine like NewEngine and returns the errors
+// of the checks that can't be registered joined, e.g. ErrInvalidCheck or
+// ErrDuplicateCheck. the Engine is returned either way with every check
+// that could be registered.
+//
+// # Example
+//
+//	engine, err := allgood.NewEngineE(
+//		allgood.WithCheckPostgresConnection(pool),
+//		allgood.WithCheckMemoryUsage(90),
+//	)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func NewEngineE(checkInitializers ...CheckInit) (*Engine, error) {
+	e := &Engine{
+		name:            DefaultName,
+		checks:          make(map[uuid.UUID]CheckConfig, len(checkInitializers)),
+		results:         make(map[uuid.UUID]models.Result, len(checkInitializers)),
+		timeout:         DefaultTimeout,
+		maxConcurrency:  DefaultMaxConcurrency,
+		duplicatePolicy: DuplicateKeepFirst,
+		telemetry:       newTelemetry(),
+	}
+	return e, e.AddChecks(checkInitializers...)
+}
 
 // HealthCheckHandler provides the http.HandlerFunc that
 // you can bind to your go web app to view the status of
 // all the checks.
+//
+// once the Engine is started the cached results of the background
+// checks are served, add ?refresh=true to the request to run every
+// check right away instead.
+//
+// the checks can be narrowed down with ?tag=, ?exclude= and ?name=,
+// e.g. ?tag=db&exclude=external.
+//
+// the response is html, json or application/health+json following the
+// Accept header, ?format=html, ?format=json or ?format=health override
+// it. a request accepting none of them gets a 406.
 func (e *Engine) HealthCheckHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		results := e.runChecks()
-		status := "ok"
-		statusCode := http.StatusOK
-
-		for _, result := range results {
-			if !result.Success {
-				status = "error"
-				statusCode = http.StatusServiceUnavailable
-				break
-			}
+		f, ok := negotiateFormat(w, r)
+		if !ok {
+			return
 		}
+		fresh, _ := strconv.ParseBool(r.URL.Query().Get("refresh"))
+		results, checkedAt := e.collectResults(r.Context(), fresh, queryFilter(r))
+		status, statusCode := overallStatus(results)
+		e.writeResults(w, r, f, results, checkedAt, status, statusCode)
+	}
+}
 
-		switch r.Header.Get("Accept") {
-		case "application/json":
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(statusCode)
-			json.NewEncoder(w).Encode(map[string]any{
-				"status": status,
-				"checks": results,
-			})
-		default:
-			w.Header().Set("Content-Type", "text/html")
-			w.WriteHeader(statusCode)
-			err := views.HealthCheckPage(results, status).Render(r.Context(), w)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-			}
+// writeResults writes results in format f, html, json or
+// application/health+json.
+func (e *Engine) writeResults(w http.ResponseWriter, r *http.Request, f format, results []models.Result, checkedAt time.Time, status string, statusCode int) {
+	w.Header().Set("Age", strconv.Itoa(int(time.Since(checkedAt).Seconds())))
+	switch f.mediaType {
+	case "application/json":
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(map[string]any{
+			"status":    status,
+			"checkedAt": checkedAt,
+			"checks":    results,
+		})
+	case HealthJSONContentType:
+		w.Header().Set("Content-Type", HealthJSONContentType)
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(e.healthResponse(results, status))
+	default:
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(statusCode)
+		err := views.HealthCheckPage(results, status).Render(r.Context(), w)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
 	}
 }
 
 // AddChecks lets you add more CheckConfig to already existing
-// checks
-func (e *Engine) AddChecks(checkInitializers ...CheckInit) {
+// checks.
+//
+// duplicates are handled following the DuplicatePolicy of the Engine.
+// every check that can be registered is, the errors of the others are
+// joined in the returned error.
+func (e *Engine) AddChecks(checkInitializers ...CheckInit) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	var errs []error
 	for _, initializer := range checkInitializers {
-		config := initializer()
-		// TODO: ignore adding check config for times in
-		// AvoidDuplicateFor it types already exist in the
-		// checks. 
-			e.checks[config.Id] = config
+		if err := e.register(initializer()); err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return errors.Join(errs...)
 }
 
-// EnableCheck lets you disable a check.
+// EnableCheck lets you enable a check.
 func (e *Engine) EnableCheck(id uuid.UUID) {
+	e.setEnabled(id, true)
+}
+
+// DisableCheck lets you disable a check.
+func (e *Engine) DisableCheck(id uuid.UUID) {
+	e.setEnabled(id, false)
+}
+
+// EnableCheckByKey lets you enable a check by its Key.
+func (e *Engine) EnableCheckByKey(key string) {
+	if check, exists := e.GetCheckByKey(key); exists {
+		e.setEnabled(check.Id, true)
+	}
+}
+
+// DisableCheckByKey lets you disable a check by its Key.
+func (e *Engine) DisableCheckByKey(key string) {
+	if check, exists := e.GetCheckByKey(key); exists {
+		e.setEnabled(check.Id, false)
+	}
+}
+
+func (e *Engine) setEnabled(id uuid.UUID, enabled bool) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	if check, exists := e.checks[id]; exists {
-		check.Enabled = true
+		check.Enabled = enabled
 		e.checks[id] = check
 	}
 }
-// DisableCheck lets you disable a check.
-func (e *Engine) DisableCheck(id uuid.UUID) {
+
+// SetName sets the name of the Engine, it identifies the service in
+// notifications.
+func (e *Engine) SetName(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.name = name
+}
+
+// SetNotifier replaces every notifier of the Engine with n, notified
+// about every check. a nil n removes every notifier.
+func (e *Engine) SetNotifier(n notify.Notifier) {
+	e.mu.Lock()
+	e.notifiers = nil
+	e.mu.Unlock()
+	if n != nil {
+		e.AddNotifier(n)
+	}
+}
+
+// SetTimeout sets the overall deadline for a run of all checks.
+// checks still running when it elapses are reported as timed out.
+// a value of zero or less waits for every check to finish.
+func (e *Engine) SetTimeout(timeout time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.timeout = timeout
+}
+
+// SetMaxConcurrency sets how many checks may run at the same time.
+// a value of zero or less lets every enabled check run at once.
+func (e *Engine) SetMaxConcurrency(n int) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	e.maxConcurrency = n
+}
+
+// execute runs checks concurrently within the Engine deadline and
+// caches their results.
+//
+// when ctx itself is done, e.g. the request went away or the Engine was
+// stopped, the checks it interrupted are reported to the caller but
+// neither cached nor notified about, they didn't really fail.
+func (e *Engine) execute(ctx context.Context, checks []CheckConfig) []models.Result {
+	e.mu.Lock()
+	timeout, workers := e.timeout, e.maxConcurrency
+	e.mu.Unlock()
+
+	if workers <= 0 || workers > len(checks) {
+		workers = len(checks)
+	}
+
+	parent := ctx
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	// cancelling on return stops checks still running past the
+	// deadline as well as the dispatcher below.
+	defer cancel()
+
+	type outcome struct {
+		index       int
+		result      models.Result
+		interrupted bool
+	}
+
+	// outcomes is buffered so checks finishing after the deadline
+	// never block on a send nobody is waiting for.
+	outcomes := make(chan outcome, len(checks))
+
+	go func() {
+		sem := make(chan struct{}, workers)
+		for i, check := range checks {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func(i int, check CheckConfig) {
+				defer func() { <-sem }()
+				result := e.runCheck(ctx, check)
+				outcomes <- outcome{index: i, result: result, interrupted: !result.Success && parent.Err() != nil}
+			}(i, check)
+		}
+	}()
+
+	begun := time.Now()
+	results := make([]models.Result, len(checks))
+	done := make([]bool, len(checks))
+	interrupted := make([]bool, len(checks))
+wait:
+	for pending := len(checks); pending > 0; pending-- {
+		select {
+		case o := <-outcomes:
+			done[o.index] = true
+			results[o.index] = o.result
+			interrupted[o.index] = o.interrupted
+		case <-ctx.Done():
+			message := fmt.Sprintf("Check timed out after %s", timeout)
+			if parent.Err() != nil {
+				message = "Check was cancelled"
+			}
+			now := time.Now()
+			for i, check := range checks {
+				if !done[i] {
+					interrupted[i] = parent.Err() != nil
+					results[i] = models.Result{
+						Id:          check.Id.String(),
+						Key:         check.Key,
+						Type:        string(check.Type),
+						Name:        check.Name,
+						Message:     message,
+						Status:      checkStatus(check, false),
+						Criticality: string(check.criticality()),
+						Tags:        check.Tags,
+						StartedAt:   begun,
+						FinishedAt:  now,
+						Duration:    now.Sub(begun),
+					}
+				}
+			}
+			break wait
+		}
+	}
+
+	e.mu.Lock()
+	var transitions []transition
+	for i, check := range checks {
+		if interrupted[i] {
+			continue
+		}
+		previous, seen := e.results[check.Id]
+		results[i] = e.cacheResult(check.Id, results[i])
+		if t, changed := detectTransition(check, previous, seen, results[i]); changed && e.isRegistered(check.Id) {
+			transitions = append(transitions, t)
+		}
+	}
+	if len(transitions) > 0 {
+		now := time.Now()
+		overall, failing := e.cachedStatus(nil)
+		for _, t := range transitions {
+			// recoveries are delivered even when silenced so incidents
+			// opened before the silence get resolved.
+			if t.to == models.StatusPass || e.silencedUntil(t.check, now).IsZero() {
+				t.dispatch(e.notifiers, t.notification(e.name, overall, failing))
+			}
+		}
+	}
+	e.notifyWatchers()
+	e.mu.Unlock()
+	return results
+}
+
+// cacheResult stores the latest result of a check and returns it with
+// the last success and failure times and the consecutive counts carried
+// over from previous runs, after applying the thresholds of the check.
+// it must be called with e.mu held.
+func (e *Engine) cacheResult(id uuid.UUID, result models.Result) models.Result {
+	previous, seen := e.results[id]
+	if seen {
+		result.LastSuccessAt = previous.LastSuccessAt
+		result.LastFailureAt = previous.LastFailureAt
+		result.Runs = previous.Runs
+		result.Failures = previous.Failures
+	}
+	maintenance := result.Status == models.StatusMaintenance
+	switch {
+	case maintenance:
+		// the check wasn't run, it keeps its counts and health.
+		result.ConsecutiveSuccesses = previous.ConsecutiveSuccesses
+		result.ConsecutiveFailures = previous.ConsecutiveFailures
+		result.Success = !seen || previous.Success
+	case result.Success:
+		result.LastSuccessAt = result.FinishedAt
+		result.ConsecutiveSuccesses = previous.ConsecutiveSuccesses + 1
+		result.Runs++
+	default:
+		result.LastFailureAt = result.FinishedAt
+		result.ConsecutiveFailures = previous.ConsecutiveFailures + 1
+		result.Runs++
+		result.Failures++
+	}
 	if check, exists := e.checks[id]; exists {
-		check.Enabled = false
-		e.checks[id] = check
+		if !maintenance {
+			// a check seen for the first time is considered healthy
+			// before, like its transitions.
+			result = applyThresholds(check, !seen || previous.Success, result)
+		}
+		result.StatusSince = result.FinishedAt
+		if seen && previous.Status == result.Status {
+			result.StatusSince = previous.StatusSince
+		}
+		e.results[id] = result
+		// startup checks must really have passed, not only be reported
+		// healthy below their failure threshold.
+		if result.ConsecutiveSuccesses > 0 && !maintenance && check.hasProbe(ProbeStartup) {
+			e.startup.passed(id)
+		}
 	}
+	return result
 }
 
-func (e *Engine) SetNotifier(n notify.Notifier) {
-	e.notifier = n
+// runCheck runs a single check with its own deadline derived from ctx
+// and records how long it took. a check in a maintenance window isn't
+// run, the others are traced and measured with OpenTelemetry.
+//
+// runCheck stops waiting for the check once its deadline passes or ctx
+// is done, a check ignoring its context is left running in the
+// background and reported as timed out.
+func (e *Engine) runCheck(ctx context.Context, check CheckConfig) models.Result {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	startedAt := time.Now()
+	e.mu.Lock()
+	window, until, inMaintenance := e.maintenanceOf(check, startedAt)
+	e.mu.Unlock()
+	if inMaintenance {
+		return maintenanceResult(check, window, until, startedAt)
+	}
+
+	ctx, span := e.startSpan(ctx, check)
+	type outcome struct {
+		status         CheckStatus
+		message, stack string
+		attempts       int
+	}
+	// outcomes is buffered so a check returning after runCheck gave up
+	// on it doesn't leak its goroutine.
+	outcomes := make(chan outcome, 1)
+	go func() {
+		var o outcome
+		o.status, o.message, o.stack, o.attempts = e.callWithRetry(ctx, check)
+		outcomes <- o
+	}()
+	o := outcome{status: CheckFail}
+	select {
+	case o = <-outcomes:
+	case <-ctx.Done():
+		switch parent.Err() {
+		case nil:
+			o.message = fmt.Sprintf("Check timed out after %s", timeout)
+		case context.DeadlineExceeded:
+			o.message = "Check timed out"
+		default:
+			o.message = "Check was cancelled"
+		}
+	}
+	finishedAt := time.Now()
+	success := o.status != CheckFail
+	status := checkStatus(check, success)
+	if o.status == CheckWarn {
+		status = models.StatusWarn
+	}
+	result := models.Result{
+		Id:          check.Id.String(),
+		Key:         check.Key,
+		Type:        string(check.Type),
+		Name:        check.Name,
+		Success:     success,
+		Message:     o.message,
+		Status:      status,
+		Criticality: string(check.criticality()),
+		Tags:        check.Tags,
+		Stack:       o.stack,
+		Attempts:    o.attempts,
+		StartedAt:   startedAt,
+		FinishedAt:  finishedAt,
+		Duration:    finishedAt.Sub(startedAt),
+	}
+	e.recordRun(ctx, span, check, result)
+	return result
+}
+
+// criticality returns the Criticality of the check, defaulting to
+// CriticalityCritical.
+func (c CheckConfig) criticality() Criticality {
+	if c.Criticality == "" {
+		return CriticalityCritical
+	}
+	return c.Criticality
+}
+
+// checkStatus maps the outcome of a check to a models.Status according
+// to its criticality. checks reporting CheckWarn are warnings whatever
+// their criticality, which callers handle.
+func checkStatus(check CheckConfig, success bool) models.Status {
+	switch {
+	case success:
+		return models.StatusPass
+	case check.criticality() == CriticalityCritical:
+		return models.StatusFail
+	default:
+		return models.StatusWarn
+	}
 }
 
-func (e *Engine) runChecks() []models.Result {
+// cachedStatus returns the overall status of the cached results of the
+// enabled checks matching filter and the names of those not passing. a
+// nil filter matches every check. it must be called with e.mu held.
+func (e *Engine) cachedStatus(filter checkFilter) (status string, failing []string) {
 	var results []models.Result
-	for id, check := range e.checks {
-		if check.Enabled {
-			success, message := check.HandlerFunc()
-			results = append(results, models.Result{
-				Id:      id.String(),
-				Name:    check.Name,
-				Success: success,
-				Message: message,
-			})
+	for _, id := range e.order {
+		result, exists := e.results[id]
+		if !exists || !e.checks[id].Enabled || (filter != nil && !filter(e.checks[id])) {
+			continue
+		}
+		results = append(results, result)
+		if result.Status != models.StatusPass && result.Status != models.StatusMaintenance {
+			failing = append(failing, result.Name)
 		}
 	}
-	return results
+	status, _ = overallStatus(results)
+	return status, failing
+}
+
+// overallStatus returns the overall status of results and the http
+// status code to respond with. failing informational checks and checks
+// in maintenance are ignored.
+func overallStatus(results []models.Result) (string, int) {
+	status := StatusOK
+	for _, result := range results {
+		if result.Criticality == string(CriticalityInfo) {
+			continue
+		}
+		switch result.Status {
+		case models.StatusFail:
+			return StatusError, http.StatusServiceUnavailable
+		case models.StatusWarn:
+			status = StatusDegraded
+		}
+	}
+	return status, http.StatusOK
 }
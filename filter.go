@@ -0,0 +1,84 @@
+package allgood
+
+import (
+	"net/http"
+	"strings"
+)
+
+// checkFilter reports whether a check should be run and reported.
+type checkFilter func(CheckConfig) bool
+
+// queryFilter builds a checkFilter from the query string of r.
+//
+//   - tag keeps checks having any of the given tags
+//   - exclude drops checks having any of the given tags
+//   - name keeps checks with any of the given names
+//
+// every parameter may be repeated or hold comma separated values.
+func queryFilter(r *http.Request) checkFilter {
+	query := r.URL.Query()
+	tags := queryValues(query["tag"])
+	excluded := queryValues(query["exclude"])
+	names := queryValues(query["name"])
+	if len(tags) == 0 && len(excluded) == 0 && len(names) == 0 {
+		return nil
+	}
+
+	return func(check CheckConfig) bool {
+		if len(tags) > 0 && !check.hasAnyTag(tags) {
+			return false
+		}
+		if len(excluded) > 0 && check.hasAnyTag(excluded) {
+			return false
+		}
+		if len(names) > 0 && !contains(names, check.Name) {
+			return false
+		}
+		return true
+	}
+}
+
+// and combines filters into one matching checks all of them match.
+// nil filters are skipped.
+func and(filters ...checkFilter) checkFilter {
+	return func(check CheckConfig) bool {
+		for _, filter := range filters {
+			if filter != nil && !filter(check) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// hasAnyTag reports whether the check has any of tags.
+func (c CheckConfig) hasAnyTag(tags []string) bool {
+	for _, tag := range c.Tags {
+		if contains(tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryValues splits comma separated query values and drops empty ones.
+func queryValues(values []string) []string {
+	var out []string
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
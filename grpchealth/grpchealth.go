@@ -0,0 +1,130 @@
+// Package grpchealth implements the grpc.health.v1.Health service from
+// the results of an allgood Engine. it lives apart from allgood so only
+// the users of gRPC depend on it.
+package grpchealth
+
+import (
+	"context"
+	"sync"
+
+	"github.com/saintmalik/allgood"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the grpc.health.v1.Health service from the results
+// of an Engine.
+//
+// the empty service name reports the overall status of the Engine. any
+// other service name reports the checks mapped to it with MapService, or
+// else the checks tagged with it. a service is serving unless its status
+// is allgood.StatusError.
+//
+// # Example
+//
+//	health := grpchealth.NewServer(engine)
+//	health.MapService("payments.v1.Payments", allgood.Route{Tags: []string{"team-payments"}})
+//	healthpb.RegisterHealthServer(grpcServer, health)
+//	engine.Start(ctx)
+//
+// Watch streams status changes as the checks run, which is every time a
+// background check finishes once the Engine is started.
+type Server struct {
+	healthpb.UnimplementedHealthServer
+
+	engine   *allgood.Engine
+	mu       sync.Mutex
+	services map[string][]allgood.Route
+}
+
+// NewServer creates a Server reporting the checks of e.
+func NewServer(e *allgood.Engine) *Server {
+	return &Server{
+		engine:   e,
+		services: make(map[string][]allgood.Route),
+	}
+}
+
+// MapService reports the checks matching any of routes as service, or
+// every check when no route is given.
+func (s *Server) MapService(service string, routes ...allgood.Route) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.services[service] = routes
+}
+
+// Check reports the current status of a service. unknown services get
+// a NotFound error.
+func (s *Server) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	routes, known := s.routes(req.GetService())
+	if !known {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+	return &healthpb.HealthCheckResponse{Status: servingStatus(s.engine.Status(ctx, routes...))}, nil
+}
+
+// Watch streams the status of a service, once right away and then every
+// time it changes. unknown services are reported as SERVICE_UNKNOWN
+// until checks are mapped or tagged for them.
+func (s *Server) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	service := req.GetService()
+	if routes, known := s.routes(service); known {
+		// runs the checks that have no result yet.
+		s.engine.Status(stream.Context(), routes...)
+	}
+	updates, stop := s.engine.Watch()
+	defer stop()
+
+	last := healthpb.HealthCheckResponse_ServingStatus(-1)
+	for {
+		current := healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		if routes, known := s.routes(service); known {
+			current = servingStatus(s.engine.CachedStatus(routes...))
+		}
+		if current != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: current}); err != nil {
+				return status.Error(codes.Canceled, "stream has ended")
+			}
+			last = current
+		}
+
+		select {
+		case <-updates:
+		case <-stream.Context().Done():
+			return status.Error(codes.Canceled, "stream has ended")
+		}
+	}
+}
+
+// routes returns the routes of a service and reports whether the
+// service is known. the empty service has no routes, which matches
+// every check.
+func (s *Server) routes(service string) ([]allgood.Route, bool) {
+	if service == "" {
+		return nil, true
+	}
+	s.mu.Lock()
+	routes, mapped := s.services[service]
+	s.mu.Unlock()
+	if mapped {
+		return routes, true
+	}
+
+	for _, check := range s.engine.ListChecks() {
+		for _, tag := range check.Tags {
+			if tag == service {
+				return []allgood.Route{{Tags: []string{service}}}, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// servingStatus maps an overall status to a gRPC serving status.
+func servingStatus(overall string) healthpb.HealthCheckResponse_ServingStatus {
+	if overall == allgood.StatusError {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
@@ -0,0 +1,179 @@
+package grpchealth
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/saintmalik/allgood"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// check returns a check with tags whose outcome is read from healthy on
+// every run.
+func check(name string, healthy *atomic.Bool, tags ...string) allgood.CheckInit {
+	return func() allgood.CheckConfig {
+		return allgood.CheckConfig{
+			Type:     "test",
+			Name:     name,
+			Enabled:  true,
+			Tags:     tags,
+			Interval: 10 * time.Millisecond,
+			HandlerFunc: func(ctx context.Context) (bool, string) {
+				return healthy.Load(), "checked"
+			},
+		}
+	}
+}
+
+func TestServerCheck(t *testing.T) {
+	var up, down atomic.Bool
+	up.Store(true)
+	engine := allgood.NewEngine(
+		check("api", &up, "team-api"),
+		check("payments db", &down, "team-payments", "db"),
+	)
+	health := NewServer(engine)
+	health.MapService("api.v1.API", allgood.Route{Names: []string{"api"}})
+	health.MapService("payments.v1.Payments", allgood.Route{Tags: []string{"team-payments"}})
+
+	tests := []struct {
+		service string
+		want    healthpb.HealthCheckResponse_ServingStatus
+	}{
+		{service: "", want: healthpb.HealthCheckResponse_NOT_SERVING},
+		{service: "api.v1.API", want: healthpb.HealthCheckResponse_SERVING},
+		{service: "payments.v1.Payments", want: healthpb.HealthCheckResponse_NOT_SERVING},
+		{service: "team-api", want: healthpb.HealthCheckResponse_SERVING},
+		{service: "db", want: healthpb.HealthCheckResponse_NOT_SERVING},
+	}
+	for _, tt := range tests {
+		t.Run(tt.service, func(t *testing.T) {
+			resp, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: tt.service})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.GetStatus() != tt.want {
+				t.Errorf("status = %s, want %s", resp.GetStatus(), tt.want)
+			}
+		})
+	}
+}
+
+func TestServerCheckPrefersMappedServices(t *testing.T) {
+	var up, down atomic.Bool
+	up.Store(true)
+	engine := allgood.NewEngine(
+		check("api", &up, "api"),
+		check("worker", &down, "api"),
+	)
+	health := NewServer(engine)
+
+	resp, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "api"})
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("Check() by tag = %v, %v, want NOT_SERVING", resp, err)
+	}
+	health.MapService("api", allgood.Route{Names: []string{"api"}})
+	resp, err = health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "api"})
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("Check() once mapped = %v, %v, want SERVING", resp, err)
+	}
+}
+
+func TestServerCheckUnknownService(t *testing.T) {
+	var up atomic.Bool
+	health := NewServer(allgood.NewEngine(check("api", &up, "api")))
+
+	_, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "billing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Check() = %v, want NotFound", err)
+	}
+}
+
+// watchStream is a healthpb.Health_WatchServer passing the responses it
+// is sent to sent.
+type watchStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent chan healthpb.HealthCheckResponse_ServingStatus
+}
+
+func (s *watchStream) Context() context.Context { return s.ctx }
+
+func (s *watchStream) Send(resp *healthpb.HealthCheckResponse) error {
+	s.sent <- resp.GetStatus()
+	return nil
+}
+
+// watch calls Watch for service from a goroutine until ctx is done and
+// returns its stream and the error it returns.
+func watch(ctx context.Context, health *Server, service string) (*watchStream, <-chan error) {
+	stream := &watchStream{ctx: ctx, sent: make(chan healthpb.HealthCheckResponse_ServingStatus, 16)}
+	done := make(chan error, 1)
+	go func() {
+		done <- health.Watch(&healthpb.HealthCheckRequest{Service: service}, stream)
+	}()
+	return stream, done
+}
+
+// next returns the next status sent on stream.
+func (s *watchStream) next(t *testing.T) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	select {
+	case got := <-s.sent:
+		return got
+	case <-time.After(2 * time.Second):
+		t.Fatal("no status sent")
+		return 0
+	}
+}
+
+func TestServerWatch(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	engine := allgood.NewEngine(check("payments db", &healthy, "payments"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine.Start(ctx)
+	defer engine.Stop()
+
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	stream, done := watch(watchCtx, NewServer(engine), "payments")
+	if got := stream.next(t); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("first status = %s, want SERVING", got)
+	}
+	healthy.Store(false)
+	if got := stream.next(t); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status after a failed run = %s, want NOT_SERVING", got)
+	}
+
+	stopWatching()
+	if err := <-done; status.Code(err) != codes.Canceled {
+		t.Errorf("Watch() = %v, want Canceled", err)
+	}
+}
+
+func TestServerWatchUnknownService(t *testing.T) {
+	engine := allgood.NewEngine()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine.Start(ctx)
+	defer engine.Stop()
+
+	stream, _ := watch(ctx, NewServer(engine), "payments")
+	if got := stream.next(t); got != healthpb.HealthCheckResponse_SERVICE_UNKNOWN {
+		t.Fatalf("first status = %s, want SERVICE_UNKNOWN", got)
+	}
+	var healthy atomic.Bool
+	if err := engine.AddChecks(check("payments db", &healthy, "payments")); err != nil {
+		t.Fatal(err)
+	}
+	if got := stream.next(t); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status once a tagged check ran = %s, want NOT_SERVING", got)
+	}
+}
@@ -0,0 +1,106 @@
+package allgood
+
+import (
+	"time"
+
+	"github.com/saintmalik/allgood/internal/models"
+)
+
+// HealthJSONContentType is the media type of the Health Check Response
+// Format for HTTP APIs draft.
+const HealthJSONContentType = "application/health+json"
+
+// Statuses of the Health Check Response Format.
+const (
+	HealthPass = "pass"
+	HealthWarn = "warn"
+	HealthFail = "fail"
+)
+
+// HealthResponse is the body of an application/health+json response as
+// described by the "Health Check Response Format for HTTP APIs" draft.
+type HealthResponse struct {
+	Status    string                         `json:"status"`
+	Version   string                         `json:"version,omitempty"`
+	ReleaseID string                         `json:"releaseId,omitempty"`
+	ServiceID string                         `json:"serviceId,omitempty"`
+	Checks    map[string][]HealthCheckResult `json:"checks"`
+}
+
+// HealthCheckResult is a measurement of a component in a HealthResponse.
+type HealthCheckResult struct {
+	ComponentID   string  `json:"componentId"`
+	ComponentType string  `json:"componentType,omitempty"`
+	ObservedValue float64 `json:"observedValue"`
+	ObservedUnit  string  `json:"observedUnit"`
+	Status        string  `json:"status"`
+	Time          string  `json:"time"`
+	Output        string  `json:"output,omitempty"`
+}
+
+// SetVersion sets the version and release id reported in
+// application/health+json responses.
+func (e *Engine) SetVersion(version, releaseID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.version = version
+	e.releaseID = releaseID
+}
+
+// healthResponse builds the HealthResponse of results. every check is
+// reported under "<name>:responseTime" with its duration in ms.
+func (e *Engine) healthResponse(results []models.Result, status string) HealthResponse {
+	e.mu.Lock()
+	response := HealthResponse{
+		Status:    healthStatus(status),
+		Version:   e.version,
+		ReleaseID: e.releaseID,
+		ServiceID: e.name,
+		Checks:    make(map[string][]HealthCheckResult, len(results)),
+	}
+	e.mu.Unlock()
+
+	for _, result := range results {
+		check := HealthCheckResult{
+			ComponentID:   result.Id,
+			ComponentType: result.Type,
+			ObservedValue: float64(result.Duration) / float64(time.Millisecond),
+			ObservedUnit:  "ms",
+			Status:        resultHealthStatus(result),
+			Time:          result.FinishedAt.Format(time.RFC3339Nano),
+		}
+		// the draft leaves the output out of passing checks.
+		if check.Status != HealthPass {
+			check.Output = result.Message
+		}
+		name := result.Name + ":responseTime"
+		response.Checks[name] = append(response.Checks[name], check)
+	}
+	return response
+}
+
+// healthStatus maps an overall status to a Health Check Response
+// Format status.
+func healthStatus(status string) string {
+	switch status {
+	case StatusError:
+		return HealthFail
+	case StatusDegraded:
+		return HealthWarn
+	default:
+		return HealthPass
+	}
+}
+
+// resultHealthStatus maps the status of a check to a Health Check
+// Response Format status, a check in maintenance is a warning.
+func resultHealthStatus(result models.Result) string {
+	switch result.Status {
+	case models.StatusPass:
+		return HealthPass
+	case models.StatusFail:
+		return HealthFail
+	default:
+		return HealthWarn
+	}
+}
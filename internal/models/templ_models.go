@@ -1,8 +1,64 @@
 package models
 
+import "time"
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+	// StatusMaintenance is the status of a check in a maintenance
+	// window, it isn't run.
+	StatusMaintenance Status = "maintenance"
+)
+
 type Result struct {
 	Id string
+	// Key is the stable, human readable identifier of the check.
+	Key string
+	// Type is the type of the check.
+	Type    string
 	Name    string
 	Success bool
 	Message string
-}
\ No newline at end of file
+	// Status is the outcome of the check, a failed check is a warning
+	// unless its Criticality is critical. a check may also report a
+	// warning itself while still succeeding.
+	Status Status
+	// StatusSince is when the check entered its current Status.
+	StatusSince time.Time
+	// Criticality is how much a failure of the check matters.
+	Criticality string
+	// Tags are the tags of the check.
+	Tags []string
+	// Stack is the trimmed stack trace of the check when it panicked.
+	Stack string
+	// Attempts is the number of times the check was tried in this run.
+	Attempts int
+	// ConsecutiveSuccesses and ConsecutiveFailures count the runs in a
+	// row the check succeeded or failed, before thresholds apply.
+	ConsecutiveSuccesses int
+	ConsecutiveFailures  int
+	// Runs and Failures count the runs of the check and those that
+	// failed, before thresholds apply.
+	Runs     int
+	Failures int
+	// StartedAt and FinishedAt are when the check run began and ended.
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Duration is how long the check run took.
+	Duration time.Duration
+	// LastSuccessAt and LastFailureAt are when the check last
+	// succeeded and failed, zero if it never has.
+	LastSuccessAt time.Time
+	LastFailureAt time.Time
+	// Maintenance is the maintenance window the check is in and
+	// MaintenanceUntil when it ends, empty when it isn't in one.
+	Maintenance      string
+	MaintenanceUntil time.Time
+	// SilencedUntil is when the silence of the notifications of the
+	// check ends, zero when it isn't silenced.
+	SilencedUntil time.Time
+}
@@ -0,0 +1,70 @@
+package views
+
+import (
+	"time"
+
+	"github.com/saintmalik/allgood/internal/models"
+)
+
+// formatTime formats t for the health check page, a zero t means the
+// event never happened.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format(time.RFC3339)
+}
+
+// pageClass returns the background color class of the health check
+// page for the overall status.
+func pageClass(status string) string {
+	switch status {
+	case "error":
+		return "bg-red-600"
+	case "degraded":
+		return "bg-yellow-400"
+	default:
+		return "bg-green-500"
+	}
+}
+
+// pageColor returns the background color of an email for the overall
+// status, it matches pageClass.
+func pageColor(status string) string {
+	switch status {
+	case "error":
+		return "#dc2626"
+	case "degraded":
+		return "#facc15"
+	default:
+		return "#22c55e"
+	}
+}
+
+// statusColor returns the color of the icon of a check status.
+func statusColor(status models.Status) string {
+	switch status {
+	case models.StatusFail:
+		return "#dc2626"
+	case models.StatusWarn:
+		return "#eab308"
+	case models.StatusMaintenance:
+		return "#3b82f6"
+	default:
+		return "#22c55e"
+	}
+}
+
+// statusIcon returns the icon of a check status.
+func statusIcon(status models.Status) string {
+	switch status {
+	case models.StatusFail:
+		return "✗"
+	case models.StatusWarn:
+		return "!"
+	case models.StatusMaintenance:
+		return "⏸"
+	default:
+		return "✓"
+	}
+}
@@ -0,0 +1,157 @@
+package allgood
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/saintmalik/allgood/internal/models"
+)
+
+// ErrInvalidMaintenanceWindow is returned when adding a maintenance
+// window that can't be scheduled.
+var ErrInvalidMaintenanceWindow = errors.New("invalid maintenance window")
+
+// MaintenanceWindow puts the checks it selects in maintenance for
+// Duration every time Schedule fires. a check is selected when its Key
+// is in Checks or it has any of Tags.
+//
+// checks in maintenance aren't run, they are reported with the
+// maintenance status, left out of the overall status and don't notify.
+//
+// # Example
+//
+//	// postgres maintenance every sunday at 3am for an hour
+//	engine.AddMaintenanceWindow(allgood.MaintenanceWindow{
+//		Name:     "postgres-upgrades",
+//		Schedule: "0 3 * * 0",
+//		Duration: time.Hour,
+//		Tags:     []string{"db"},
+//	})
+type MaintenanceWindow struct {
+	// Name identifies the window.
+	Name string
+	// Schedule is a five field cron expression of when the window
+	// starts, e.g. "30 2 * * 1-5".
+	Schedule string
+	// Duration is how long the window lasts.
+	Duration time.Duration
+	// Location is the time zone of Schedule, time.Local when nil.
+	Location *time.Location
+	Checks   []string
+	Tags     []string
+}
+
+// maintenanceWindow is a MaintenanceWindow with its parsed schedule.
+type maintenanceWindow struct {
+	MaintenanceWindow
+	schedule cronSchedule
+}
+
+// selects reports whether the window applies to check.
+func (w MaintenanceWindow) selects(check CheckConfig) bool {
+	return contains(w.Checks, check.Key) || check.hasAnyTag(w.Tags)
+}
+
+// AddMaintenanceWindow adds a maintenance window, a window with the
+// same Name is replaced.
+func (e *Engine) AddMaintenanceWindow(w MaintenanceWindow) error {
+	if w.Name == "" {
+		return fmt.Errorf("%w: no name", ErrInvalidMaintenanceWindow)
+	}
+	if len(w.Checks) == 0 && len(w.Tags) == 0 {
+		return fmt.Errorf("%w %s: no checks or tags", ErrInvalidMaintenanceWindow, w.Name)
+	}
+	if w.Duration < time.Minute {
+		return fmt.Errorf("%w %s: duration under a minute", ErrInvalidMaintenanceWindow, w.Name)
+	}
+	schedule, err := parseCron(w.Schedule)
+	if err != nil {
+		return fmt.Errorf("%w %s: %w", ErrInvalidMaintenanceWindow, w.Name, err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.removeMaintenanceWindow(w.Name)
+	e.maintenance = append(e.maintenance, maintenanceWindow{MaintenanceWindow: w, schedule: schedule})
+	return nil
+}
+
+// RemoveMaintenanceWindow removes a maintenance window. it reports
+// whether the window was registered.
+func (e *Engine) RemoveMaintenanceWindow(name string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.removeMaintenanceWindow(name)
+}
+
+// MaintenanceWindows returns the maintenance windows of the Engine.
+func (e *Engine) MaintenanceWindows() []MaintenanceWindow {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	windows := make([]MaintenanceWindow, len(e.maintenance))
+	for i, w := range e.maintenance {
+		windows[i] = w.MaintenanceWindow
+	}
+	return windows
+}
+
+// removeMaintenanceWindow removes a maintenance window. it must be
+// called with e.mu held.
+func (e *Engine) removeMaintenanceWindow(name string) bool {
+	for i, w := range e.maintenance {
+		if w.Name == name {
+			e.maintenance = append(e.maintenance[:i], e.maintenance[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// activeAt returns when the window ends if it is active at t, which is
+// when it last started less than Duration before t.
+func (w maintenanceWindow) activeAt(t time.Time) (until time.Time, active bool) {
+	location := w.Location
+	if location == nil {
+		location = time.Local
+	}
+	t = t.In(location)
+	start, found := w.schedule.latest(t, t.Add(-w.Duration))
+	if !found {
+		return time.Time{}, false
+	}
+	return start.Add(w.Duration), true
+}
+
+// maintenanceOf returns the maintenance window check is in at t and when
+// it ends. it must be called with e.mu held.
+func (e *Engine) maintenanceOf(check CheckConfig, t time.Time) (name string, until time.Time, active bool) {
+	for _, w := range e.maintenance {
+		if !w.selects(check) {
+			continue
+		}
+		if end, ok := w.activeAt(t); ok && end.After(until) {
+			name, until, active = w.Name, end, true
+		}
+	}
+	return name, until, active
+}
+
+// maintenanceResult is the result of a check that isn't run because it
+// is in a maintenance window.
+func maintenanceResult(check CheckConfig, window string, until, now time.Time) models.Result {
+	return models.Result{
+		Id:               check.Id.String(),
+		Key:              check.Key,
+		Type:             string(check.Type),
+		Name:             check.Name,
+		Message:          fmt.Sprintf("In maintenance window %s until %s", window, until.Format(time.RFC3339)),
+		Status:           models.StatusMaintenance,
+		Criticality:      string(check.criticality()),
+		Tags:             check.Tags,
+		Maintenance:      window,
+		MaintenanceUntil: until,
+		StartedAt:        now,
+		FinishedAt:       now,
+	}
+}
@@ -0,0 +1,100 @@
+package allgood
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/saintmalik/allgood/internal/models"
+)
+
+func TestMaintenanceWindowActiveAt(t *testing.T) {
+	utc := time.UTC
+	tests := []struct {
+		name     string
+		schedule string
+		duration time.Duration
+		at       time.Time
+		until    time.Time
+	}{
+		{name: "inside", schedule: "0 3 * * 0", duration: time.Hour, at: time.Date(2026, 10, 18, 3, 30, 0, 0, utc), until: time.Date(2026, 10, 18, 4, 0, 0, 0, utc)},
+		{name: "at the start", schedule: "0 3 * * 0", duration: time.Hour, at: time.Date(2026, 10, 18, 3, 0, 0, 0, utc), until: time.Date(2026, 10, 18, 4, 0, 0, 0, utc)},
+		{name: "at the end", schedule: "0 3 * * 0", duration: time.Hour, at: time.Date(2026, 10, 18, 4, 0, 0, 0, utc)},
+		{name: "before", schedule: "0 3 * * 0", duration: time.Hour, at: time.Date(2026, 10, 18, 2, 59, 0, 0, utc)},
+		{name: "other day", schedule: "0 3 * * 0", duration: time.Hour, at: time.Date(2026, 10, 19, 3, 30, 0, 0, utc)},
+		{name: "spans days", schedule: "30 22 * * 5", duration: 3 * 24 * time.Hour, at: time.Date(2026, 10, 19, 12, 0, 0, 0, utc), until: time.Date(2026, 10, 19, 22, 30, 0, 0, utc)},
+		{name: "latest start", schedule: "*/15 * * * *", duration: time.Hour, at: time.Date(2026, 10, 18, 3, 50, 0, 0, utc), until: time.Date(2026, 10, 18, 4, 45, 0, 0, utc)},
+		{name: "week long", schedule: "0 0 1 * *", duration: 7 * 24 * time.Hour, at: time.Date(2026, 11, 7, 23, 59, 0, 0, utc), until: time.Date(2026, 11, 8, 0, 0, 0, 0, utc)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := parseCron(tt.schedule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := maintenanceWindow{MaintenanceWindow: MaintenanceWindow{Duration: tt.duration, Location: utc}, schedule: schedule}
+			until, active := w.activeAt(tt.at)
+			if active != !tt.until.IsZero() || !until.Equal(tt.until) {
+				t.Fatalf("activeAt(%s) = %s, %t, want %s", tt.at, until, active, tt.until)
+			}
+		})
+	}
+}
+
+func TestCronLatestMatchesMinuteByMinute(t *testing.T) {
+	expressions := []string{"0 3 * * 0", "*/7 1-5 * * *", "15 4 13 * 5", "0 0 29 2 *", "30 * 1,15 1-6 1-5", "@hourly"}
+	locations := []*time.Location{time.UTC, time.FixedZone("UTC+5:30", 5*3600+1800)}
+	if newYork, err := time.LoadLocation("America/New_York"); err == nil {
+		locations = append(locations, newYork)
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, expr := range expressions {
+		schedule, err := parseCron(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 200; i++ {
+			location := locations[rng.Intn(len(locations))]
+			at := time.Unix(1767225600+rng.Int63n(2*365*24*3600), 0).In(location)
+			earliest := at.Add(-time.Duration(rng.Int63n(int64(10 * 24 * time.Hour))))
+
+			var want time.Time
+			for start := at.Truncate(time.Minute); start.After(earliest); start = start.Add(-time.Minute) {
+				if schedule.minutes[start.Minute()] && schedule.hours[start.Hour()] && schedule.matchesDay(start) {
+					want = start
+					break
+				}
+			}
+			got, found := schedule.latest(at, earliest)
+			if found != !want.IsZero() || (found && !got.Equal(want)) {
+				t.Fatalf("%s: latest(%s, %s) = %s, %t, want %s", expr, at, earliest, got, found, want)
+			}
+		}
+	}
+}
+
+func TestChecksInMaintenanceAreExcluded(t *testing.T) {
+	ran := false
+	e := NewEngine(func() CheckConfig {
+		return CheckConfig{Type: "test", Name: "db", Enabled: true, Tags: []string{"db"}, HandlerFunc: func(context.Context) (bool, string) {
+			ran = true
+			return false, "down"
+		}}
+	})
+	err := e.AddMaintenanceWindow(MaintenanceWindow{Name: "always", Schedule: "* * * * *", Duration: time.Hour, Tags: []string{"db"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, _ := e.collectResults(context.Background(), true, nil)
+	if ran {
+		t.Fatal("ran a check in maintenance")
+	}
+	if results[0].Status != models.StatusMaintenance || results[0].Maintenance != "always" {
+		t.Fatalf("result = %+v, want the check in maintenance", results[0])
+	}
+	if status, _ := overallStatus(results); status != StatusOK {
+		t.Fatalf("overall status = %s, want %s", status, StatusOK)
+	}
+}
@@ -0,0 +1,123 @@
+package allgood
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/saintmalik/allgood/internal/models"
+)
+
+// MetricsContentType is the media type of the Prometheus text
+// exposition format.
+const MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// checkStatuses are the values of the status label of the
+// allgood_check_status metric.
+var checkStatuses = []models.Status{models.StatusPass, models.StatusWarn, models.StatusFail, models.StatusMaintenance}
+
+// MetricsHandler provides an http.HandlerFunc serving the results of the
+// checks in the Prometheus text exposition format. the checks are run
+// the same way HealthCheckHandler runs them and accept the same filters.
+//
+// every check metric is labelled with the name, key, type and comma
+// separated tags of the check.
+func (e *Engine) MetricsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fresh, _ := strconv.ParseBool(r.URL.Query().Get("refresh"))
+		results, _ := e.collectResults(r.Context(), fresh, queryFilter(r))
+		status, _ := overallStatus(results)
+		e.mu.Lock()
+		name := e.name
+		e.mu.Unlock()
+
+		w.Header().Set("Content-Type", MetricsContentType)
+
+		writeMetric(w, "allgood_info", "gauge", "Information about the allgood engine.")
+		writeSample(w, "allgood_info", labels("service", name, "version", Version), 1)
+
+		writeMetric(w, "allgood_status", "gauge", "Overall status of the checks, 1 for the current status.")
+		for _, s := range []string{StatusOK, StatusDegraded, StatusError} {
+			writeSample(w, "allgood_status", labels("service", name, "status", s), boolValue(s == status))
+		}
+
+		writeMetric(w, "allgood_check_status", "gauge", "Status of the check, 1 for the current status.")
+		for _, result := range results {
+			for _, s := range checkStatuses {
+				writeSample(w, "allgood_check_status", checkLabels(result, "status", string(s)), boolValue(s == result.Status))
+			}
+		}
+
+		writeMetric(w, "allgood_check_duration_seconds", "gauge", "Duration of the last run of the check.")
+		for _, result := range results {
+			writeSample(w, "allgood_check_duration_seconds", checkLabels(result), result.Duration.Seconds())
+		}
+
+		writeMetric(w, "allgood_check_runs_total", "counter", "Runs of the check.")
+		for _, result := range results {
+			writeSample(w, "allgood_check_runs_total", checkLabels(result), float64(result.Runs))
+		}
+
+		writeMetric(w, "allgood_check_failures_total", "counter", "Failed runs of the check.")
+		for _, result := range results {
+			writeSample(w, "allgood_check_failures_total", checkLabels(result), float64(result.Failures))
+		}
+
+		writeMetric(w, "allgood_check_last_success_timestamp_seconds", "gauge", "Unix time of the last success of the check, 0 if it never succeeded.")
+		for _, result := range results {
+			var timestamp float64
+			if !result.LastSuccessAt.IsZero() {
+				timestamp = float64(result.LastSuccessAt.UnixNano()) / 1e9
+			}
+			writeSample(w, "allgood_check_last_success_timestamp_seconds", checkLabels(result), timestamp)
+		}
+	}
+}
+
+// writeMetric writes the HELP and TYPE lines of a metric.
+func writeMetric(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// writeSample writes a sample of a metric.
+func writeSample(w io.Writer, name, labels string, value float64) {
+	fmt.Fprintf(w, "%s{%s} %s\n", name, labels, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// checkLabels returns the labels of a check followed by extra label
+// name and value pairs.
+func checkLabels(result models.Result, extra ...string) string {
+	return labels(append([]string{
+		"check", result.Name,
+		"key", result.Key,
+		"type", result.Type,
+		"tags", strings.Join(result.Tags, ","),
+	}, extra...)...)
+}
+
+// labels formats label name and value pairs.
+func labels(pairs ...string) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+// labelEscaper escapes label values as the exposition format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
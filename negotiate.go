@@ -0,0 +1,138 @@
+package allgood
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// format is an output format of the health endpoints.
+type format struct {
+	// name selects the format with ?format=.
+	name      string
+	mediaType string
+}
+
+// htmlFormat is the format of requests without an Accept header, e.g.
+// browsers following a link.
+var htmlFormat = format{name: "html", mediaType: "text/html"}
+
+// formats are the output formats of the health endpoints, in the order
+// they are preferred when a client accepts several equally. machine
+// formats come first so clients only sending */* get json.
+var formats = []format{
+	{name: "json", mediaType: "application/json"},
+	{name: "health", mediaType: HealthJSONContentType},
+	htmlFormat,
+}
+
+// acceptRange is a media range of an Accept header.
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// negotiateFormat picks the output format of a request from its ?format=
+// parameter, or else its Accept header. when none of the formats is
+// acceptable it responds with 406 and reports false.
+func negotiateFormat(w http.ResponseWriter, r *http.Request) (format, bool) {
+	w.Header().Add("Vary", "Accept")
+	f, ok := acceptedFormat(r)
+	if !ok {
+		mediaTypes := make([]string, len(formats))
+		for i, f := range formats {
+			mediaTypes[i] = f.mediaType
+		}
+		http.Error(w, fmt.Sprintf("not acceptable, supported media types are %s", strings.Join(mediaTypes, ", ")), http.StatusNotAcceptable)
+	}
+	return f, ok
+}
+
+// acceptedFormat picks the format of a request, a request without an
+// Accept header gets html. among the formats of the highest quality the
+// one matched by the most specific range wins, so html is only picked
+// over json when text/html or text/* is listed.
+func acceptedFormat(r *http.Request) (format, bool) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		for _, f := range formats {
+			if f.name == name || f.mediaType == name {
+				return f, true
+			}
+		}
+		return format{}, false
+	}
+
+	accept := r.Header.Values("Accept")
+	if len(accept) == 0 {
+		return htmlFormat, true
+	}
+	ranges := parseAccept(strings.Join(accept, ","))
+	if len(ranges) == 0 {
+		return htmlFormat, true
+	}
+
+	var best format
+	bestQ, bestSpecificity := 0.0, -1
+	for _, f := range formats {
+		q, specificity := acceptQuality(ranges, f.mediaType)
+		if q > bestQ || (q > 0 && q == bestQ && specificity > bestSpecificity) {
+			best, bestQ, bestSpecificity = f, q, specificity
+		}
+	}
+	return best, bestQ > 0
+}
+
+// parseAccept parses the media ranges of an Accept header, malformed
+// ranges are skipped.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		typ, subtype, found := strings.Cut(mediaType, "/")
+		if !found || typ == "" || subtype == "" || (typ == "*" && subtype != "*") {
+			continue
+		}
+		q := 1.0
+		if value, exists := params["q"]; exists {
+			if q, err = strconv.ParseFloat(value, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{typ: typ, subtype: subtype, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality of mediaType under the most
+// specific of ranges matching it and how specific that range is, from 0
+// for */* to 2 for the media type itself. the quality is zero when no
+// range matches.
+func acceptQuality(ranges []acceptRange, mediaType string) (float64, int) {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q, specificity
+}
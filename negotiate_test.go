@@ -0,0 +1,66 @@
+package allgood
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAcceptedFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept []string
+		query  string
+		want   string
+		ok     bool
+	}{
+		{name: "no accept header", want: "text/html", ok: true},
+		{name: "curl", accept: []string{"*/*"}, want: "application/json", ok: true},
+		{name: "wildcard with quality", accept: []string{"*/*;q=0.8"}, want: "application/json", ok: true},
+		{name: "browser", accept: []string{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"}, want: "text/html", ok: true},
+		{name: "json over plain text", accept: []string{"application/json, text/plain"}, want: "application/json", ok: true},
+		{name: "health json", accept: []string{"application/health+json"}, want: HealthJSONContentType, ok: true},
+		{name: "quality decides", accept: []string{"application/json;q=0.5, text/html"}, want: "text/html", ok: true},
+		{name: "specific over wildcard", accept: []string{"text/html, */*"}, want: "text/html", ok: true},
+		{name: "type wildcard", accept: []string{"text/*"}, want: "text/html", ok: true},
+		{name: "several headers", accept: []string{"text/plain", "application/json"}, want: "application/json", ok: true},
+		{name: "refused", accept: []string{"application/json;q=0, */*;q=0"}, ok: false},
+		{name: "unsupported", accept: []string{"image/png"}, ok: false},
+		{name: "malformed header", accept: []string{";;"}, want: "text/html", ok: true},
+		{name: "format override", accept: []string{"text/html"}, query: "?format=json", want: "application/json", ok: true},
+		{name: "media type override", query: "?format=application/health%2Bjson", want: HealthJSONContentType, ok: true},
+		{name: "unknown override", query: "?format=xml", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			for _, accept := range tt.accept {
+				r.Header.Add("Accept", accept)
+			}
+			f, ok := acceptedFormat(r)
+			if ok != tt.ok || f.mediaType != tt.want {
+				t.Fatalf("acceptedFormat() = %q, %t, want %q, %t", f.mediaType, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestHealthCheckHandlerNotAcceptable(t *testing.T) {
+	ran := false
+	e := NewEngine(testCheck("db", func(ctx context.Context) (bool, string) {
+		ran = true
+		return true, "ok"
+	}))
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "image/png")
+	e.HealthCheckHandler()(rec, r)
+
+	if rec.Code != http.StatusNotAcceptable {
+		t.Fatalf("status code = %d, want 406", rec.Code)
+	}
+	if ran {
+		t.Fatal("checks ran for a request that can't be answered")
+	}
+}
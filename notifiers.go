@@ -0,0 +1,230 @@
+package allgood
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/saintmalik/allgood/notify"
+)
+
+// NotifierQueueSize is how many notifications may wait for a notifier
+// before new ones are dropped.
+const NotifierQueueSize = 100
+
+// ErrNotifierQueueFull is reported to the NotifyErrorHandler when a
+// notification is dropped because the queue of its notifier is full.
+var ErrNotifierQueueFull = errors.New("notifier queue full")
+
+// NotifyErrorHandler is called when a notification can't be delivered,
+// with the notifier, the notification and the error the notifier
+// returned or ErrNotifierQueueFull. use it to log or count lost
+// notifications.
+type NotifyErrorHandler func(notifier notify.Notifier, n notify.Notification, err error)
+
+// SetNotifyErrorHandler sets the function called when a notification
+// can't be delivered. without one delivery errors are ignored.
+func (e *Engine) SetNotifyErrorHandler(handler NotifyErrorHandler) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.notifyErrorHandler = handler
+}
+
+// notifyError reports a notification that can't be delivered to the
+// NotifyErrorHandler.
+func (e *Engine) notifyError(notifier notify.Notifier, n notify.Notification, err error) {
+	e.mu.Lock()
+	handler := e.notifyErrorHandler
+	e.mu.Unlock()
+	if handler != nil {
+		handler(notifier, n, err)
+	}
+}
+
+// Route selects the checks a notifier is notified about. a check
+// matches when it matches every non empty field, and a field when the
+// check has any of its values.
+//
+// # Example
+//
+//	// payments database failures page someone
+//	engine.AddNotifier(pagerDuty, allgood.Route{
+//		Tags:          []string{"team-payments"},
+//		Types:         []allgood.CheckType{allgood.CheckTypePostgresConnection},
+//		Criticalities: []allgood.Criticality{allgood.CriticalityCritical},
+//	})
+type Route struct {
+	Tags          []string
+	Types         []CheckType
+	Criticalities []Criticality
+	Names         []string
+}
+
+// filter builds the checkFilter of the route.
+func (r Route) filter() checkFilter {
+	return func(check CheckConfig) bool {
+		if len(r.Tags) > 0 && !check.hasAnyTag(r.Tags) {
+			return false
+		}
+		if len(r.Types) > 0 && !containsType(r.Types, check.Type) {
+			return false
+		}
+		if len(r.Criticalities) > 0 && !containsCriticality(r.Criticalities, check.criticality()) {
+			return false
+		}
+		if len(r.Names) > 0 && !contains(r.Names, check.Name) {
+			return false
+		}
+		return true
+	}
+}
+
+// routedNotifier delivers the notifications of the checks matching its
+// routes to a notifier, in order and off the check path.
+type routedNotifier struct {
+	engine   *Engine
+	notifier notify.Notifier
+	filters  []checkFilter
+
+	mu    sync.Mutex
+	idle  *sync.Cond
+	queue []notify.Notification
+	// dropped are the notifications dropped because the queue was
+	// full, reported by the delivering goroutine.
+	dropped []notify.Notification
+	running bool
+}
+
+// matches reports whether check matches any of the routes, a notifier
+// without routes matches every check.
+func (n *routedNotifier) matches(check CheckConfig) bool {
+	if len(n.filters) == 0 {
+		return true
+	}
+	for _, filter := range n.filters {
+		if filter(check) {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueue queues a notification and starts delivering the queue unless
+// it is already being delivered. a full queue is always being delivered,
+// the notifications it drops are reported from there rather than here
+// where the engine mutex is held.
+func (n *routedNotifier) enqueue(notification notify.Notification) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.queue) >= NotifierQueueSize {
+		if len(n.dropped) < NotifierQueueSize {
+			n.dropped = append(n.dropped, notification)
+		}
+		return
+	}
+	n.queue = append(n.queue, notification)
+	if !n.running {
+		n.running = true
+		go n.deliver()
+	}
+}
+
+// deliver notifies the notifier of queued notifications until the queue
+// is empty, and reports the notifications dropped meanwhile.
+func (n *routedNotifier) deliver() {
+	for {
+		n.mu.Lock()
+		dropped := n.dropped
+		n.dropped = nil
+		if len(n.queue) == 0 && len(dropped) == 0 {
+			n.running = false
+			n.idle.Broadcast()
+			n.mu.Unlock()
+			return
+		}
+		var notification notify.Notification
+		queued := len(n.queue) > 0
+		if queued {
+			notification = n.queue[0]
+			n.queue = n.queue[1:]
+		}
+		n.mu.Unlock()
+
+		for _, d := range dropped {
+			n.engine.notifyError(n.notifier, d, ErrNotifierQueueFull)
+		}
+		if !queued {
+			continue
+		}
+		if err := n.notifier.Notify(notification); err != nil {
+			n.engine.notifyError(n.notifier, notification, err)
+		}
+	}
+}
+
+// wait waits until the queue has been delivered.
+func (n *routedNotifier) wait() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for n.running {
+		n.idle.Wait()
+	}
+}
+
+// AddNotifier adds a notifier called every time a check matching any of
+// routes moves between healthy, degraded and failing. a notifier added
+// without routes is notified about every check.
+//
+// every notifier is called from its own goroutine with its own queue of
+// up to NotifierQueueSize notifications, a slow notifier never holds up
+// the checks or the other notifiers.
+func (e *Engine) AddNotifier(n notify.Notifier, routes ...Route) {
+	notifier := &routedNotifier{engine: e, notifier: n}
+	notifier.idle = sync.NewCond(&notifier.mu)
+	for _, route := range routes {
+		notifier.filters = append(notifier.filters, route.filter())
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.notifiers = append(e.notifiers, notifier)
+}
+
+// FlushNotifications waits until every queued notification has been
+// delivered or ctx is done.
+func (e *Engine) FlushNotifications(ctx context.Context) error {
+	e.mu.Lock()
+	notifiers := e.notifiers
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		for _, n := range notifiers {
+			n.wait()
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func containsType(types []CheckType, t CheckType) bool {
+	for _, v := range types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+func containsCriticality(criticalities []Criticality, c Criticality) bool {
+	for _, v := range criticalities {
+		if v == c {
+			return true
+		}
+	}
+	return false
+}
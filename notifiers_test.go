@@ -0,0 +1,101 @@
+package allgood
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/saintmalik/allgood/notify"
+)
+
+func TestRoutes(t *testing.T) {
+	check := CheckConfig{Type: CheckTypePostgresConnection, Name: "payments", Tags: []string{"db", "team-payments"}}
+	tests := []struct {
+		name   string
+		routes []Route
+		want   bool
+	}{
+		{name: "no routes", want: true},
+		{name: "tag", routes: []Route{{Tags: []string{"team-payments"}}}, want: true},
+		{name: "other tag", routes: []Route{{Tags: []string{"team-search"}}}, want: false},
+		{name: "every field", routes: []Route{{
+			Tags:          []string{"db"},
+			Types:         []CheckType{CheckTypePostgresConnection},
+			Criticalities: []Criticality{CriticalityCritical},
+			Names:         []string{"payments"},
+		}}, want: true},
+		{name: "one field off", routes: []Route{{Tags: []string{"db"}, Criticalities: []Criticality{CriticalityWarning}}}, want: false},
+		{name: "any route", routes: []Route{{Names: []string{"search"}}, {Types: []CheckType{CheckTypePostgresConnection}}}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine()
+			e.AddNotifier(&recorder{}, tt.routes...)
+			if got := e.notifiers[0].matches(check); got != tt.want {
+				t.Fatalf("matches() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifierErrorsAreReported(t *testing.T) {
+	failure := errors.New("webhook down")
+	e := NewEngine(testCheck("db", func(context.Context) (bool, string) {
+		return false, "down"
+	}))
+	failing := notify.NotifierFunc(func(notify.Notification) error {
+		return failure
+	})
+	e.AddNotifier(failing)
+
+	var mu sync.Mutex
+	var reported []error
+	e.SetNotifyErrorHandler(func(notifier notify.Notifier, n notify.Notification, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, err)
+	})
+
+	e.collectResults(context.Background(), true, nil)
+	if err := e.FlushNotifications(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 || !errors.Is(reported[0], failure) {
+		t.Fatalf("reported %v, want %v", reported, failure)
+	}
+}
+
+func TestFullQueueDropsAreReported(t *testing.T) {
+	release := make(chan struct{})
+	e := NewEngine()
+	e.AddNotifier(notify.NotifierFunc(func(notify.Notification) error {
+		<-release
+		return nil
+	}))
+	var dropped atomic.Int32
+	e.SetNotifyErrorHandler(func(_ notify.Notifier, _ notify.Notification, err error) {
+		if errors.Is(err, ErrNotifierQueueFull) {
+			dropped.Add(1)
+		}
+	})
+
+	e.mu.Lock()
+	for i := 0; i < NotifierQueueSize+2; i++ {
+		e.notifiers[0].enqueue(notify.Notification{CheckName: "db"})
+	}
+	e.mu.Unlock()
+	close(release)
+	if err := e.FlushNotifications(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first notification may already be delivering when the queue
+	// fills up.
+	if n := dropped.Load(); n < 1 || n > 2 {
+		t.Fatalf("reported %d dropped notifications, want 1 or 2", n)
+	}
+}
@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultTimeout is the timeout of the http client used by notifiers
+// that aren't given one.
+const DefaultTimeout = 10 * time.Second
+
+var defaultClient = &http.Client{Timeout: DefaultTimeout}
+
+// maxErrorBody is how much of a failed response body is kept in the
+// returned error.
+const maxErrorBody = 512
+
+// post sends body to target and treats any non 2xx response as an
+// error. errors never include target, which may hold a secret like a
+// bot token or a webhook path.
+func post(ctx context.Context, client *http.Client, target, contentType string, body []byte, headers map[string]string) error {
+	if client == nil {
+		client = defaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", redactURL(err))
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", redactURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(snippet)}
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// redactURL strips the request url from err.
+func redactURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// StatusError is returned by notifiers when the receiving service
+// responds with a non 2xx status code.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("unexpected status code %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status code %d: %s", e.StatusCode, e.Body)
+}
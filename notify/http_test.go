@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPost(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   *StatusError
+	}{
+		{name: "ok", status: http.StatusOK},
+		{name: "no content", status: http.StatusNoContent},
+		{name: "redirect", status: http.StatusNotModified, want: &StatusError{StatusCode: http.StatusNotModified}},
+		{name: "client error", status: http.StatusNotFound, body: "no such hook", want: &StatusError{StatusCode: http.StatusNotFound, Body: "no such hook"}},
+		{name: "server error", status: http.StatusBadGateway, body: strings.Repeat("x", 2*maxErrorBody), want: &StatusError{StatusCode: http.StatusBadGateway, Body: strings.Repeat("x", maxErrorBody)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-Test") != "yes" || r.Header.Get("Content-Type") != "text/plain" {
+					t.Errorf("headers = %v", r.Header)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			err := post(context.Background(), server.Client(), server.URL, "text/plain", []byte("hi"), map[string]string{"X-Test": "yes"})
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("post() = %v, want nil", err)
+				}
+				return
+			}
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || *statusErr != *tt.want {
+				t.Fatalf("post() = %#v, want %#v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 20 * time.Millisecond}
+	err := post(context.Background(), client, server.URL, "text/plain", nil, nil)
+	var statusErr *StatusError
+	if err == nil || errors.As(err, &statusErr) {
+		t.Fatalf("post() = %v, want a timeout", err)
+	}
+}
@@ -0,0 +1,104 @@
+// Package notify provides the notifiers an allgood Engine calls when
+// its checks change status.
+package notify
+
+import (
+	"fmt"
+	"time"
+)
+
+// Statuses a check can be in.
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+// Notification is sent when a check moves from one status to another.
+type Notification struct {
+	// Service is the name of the Engine that ran the check.
+	Service string
+	// CheckID, CheckKey, CheckName and CheckType identify the check.
+	CheckID   string
+	CheckKey  string
+	CheckName string
+	CheckType string
+	// Criticality is how much a failure of the check matters, one of
+	// "critical", "warning" or "info".
+	Criticality string
+	// Tags are the tags of the check.
+	Tags []string
+	// PreviousStatus and Status are the status the check moved from
+	// and to, one of StatusPass, StatusWarn or StatusFail.
+	PreviousStatus string
+	Status         string
+	// PreviousStatusDuration is how long the check was in its
+	// previous status, zero when the check had not run before.
+	PreviousStatusDuration time.Duration
+	// Message is the message of the check run that moved it.
+	Message string
+	// StartedAt, FinishedAt and Duration time the check run.
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Duration   time.Duration
+	// OverallStatus is the status of the Engine after the transition,
+	// one of "ok", "degraded" or "error".
+	OverallStatus string
+	// FailingChecks are the names of the checks not passing after the
+	// transition.
+	FailingChecks []string
+}
+
+// String describes the notification in plain english.
+func (n Notification) String() string {
+	message := fmt.Sprintf("%s (%s) is now %s", n.CheckName, n.CheckType, StateName(n.Status))
+	if n.PreviousStatusDuration > 0 {
+		message += fmt.Sprintf(", was %s for %s", StateName(n.PreviousStatus), n.PreviousStatusDuration.Round(time.Millisecond))
+	} else {
+		message += fmt.Sprintf(", was %s", StateName(n.PreviousStatus))
+	}
+	return message + ": " + n.Message
+}
+
+// Recovered reports whether the check moved back to StatusPass.
+func (n Notification) Recovered() bool {
+	return n.Status == StatusPass
+}
+
+// StateName names a check status the way notifications do, e.g.
+// "failing" for StatusFail.
+func StateName(status string) string {
+	switch status {
+	case StatusFail:
+		return "failing"
+	case StatusWarn:
+		return "degraded"
+	default:
+		return "healthy"
+	}
+}
+
+// Notifier is notified of check status transitions.
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// NotifierFunc lets you use a function as a Notifier.
+type NotifierFunc func(n Notification) error
+
+func (f NotifierFunc) Notify(n Notification) error {
+	return f(n)
+}
+
+// MessageNotifier is a notifier taking a plain message.
+type MessageNotifier interface {
+	Notify(message string) error
+}
+
+// FromMessageNotifier adapts a MessageNotifier into a Notifier, it is
+// notified with the String of every Notification.
+func FromMessageNotifier(m MessageNotifier) Notifier {
+	return NotifierFunc(func(n Notification) error {
+		return m.Notify(n.String())
+	})
+}
@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultPagerDutyURL is the PagerDuty Events API v2 enqueue endpoint.
+const DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// maxPagerDutySummary is the longest summary PagerDuty accepts.
+const maxPagerDutySummary = 1024
+
+// PagerDuty event actions.
+const (
+	PagerDutyTrigger = "trigger"
+	PagerDutyResolve = "resolve"
+)
+
+// PagerDutyNotifier triggers a PagerDuty incident when a check stops
+// passing and resolves it when the check recovers. every check maps to
+// a single incident through a dedup key made of the service name and
+// the check id.
+type PagerDutyNotifier struct {
+	// RoutingKey is the integration key of the PagerDuty service.
+	RoutingKey string
+	// URL is the endpoint events are sent to, DefaultPagerDutyURL when
+	// empty.
+	URL string
+	// Source is the source of the triggered events, the service name
+	// of the notification when empty.
+	Source string
+	// Client is the http client used to send events. a client with
+	// DefaultTimeout is used when it is nil.
+	Client *http.Client
+}
+
+// NewPagerDutyNotifier creates a PagerDutyNotifier sending events with
+// routingKey.
+func NewPagerDutyNotifier(routingKey string) *PagerDutyNotifier {
+	return &PagerDutyNotifier{
+		RoutingKey: routingKey,
+		Client:     &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+// PagerDutyEvent is the body of a PagerDuty Events API v2 request.
+type PagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *PagerDutyPayload `json:"payload,omitempty"`
+}
+
+// PagerDutyPayload describes the incident of a trigger event.
+type PagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Timestamp     string         `json:"timestamp,omitempty"`
+	Component     string         `json:"component,omitempty"`
+	Group         string         `json:"group,omitempty"`
+	Class         string         `json:"class,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+// PagerDutyDedupKey returns the dedup key of the incident of a check.
+func PagerDutyDedupKey(service, checkID string) string {
+	return service + ":" + checkID
+}
+
+// PagerDutySeverity maps the criticality and status of a check to a
+// PagerDuty severity. a check that is only degraded is never more than
+// a warning.
+func PagerDutySeverity(criticality, status string) string {
+	if status == StatusWarn {
+		return "warning"
+	}
+	switch criticality {
+	case "warning":
+		return "warning"
+	case "info":
+		return "info"
+	default:
+		return "critical"
+	}
+}
+
+func (p *PagerDutyNotifier) Notify(n Notification) error {
+	body, err := json.Marshal(p.event(n))
+	if err != nil {
+		return fmt.Errorf("pagerduty: encoding event: %w", err)
+	}
+	url := p.URL
+	if url == "" {
+		url = DefaultPagerDutyURL
+	}
+	if err := post(context.Background(), p.Client, url, "application/json", body, nil); err != nil {
+		return fmt.Errorf("pagerduty: %w", err)
+	}
+	return nil
+}
+
+// event builds the PagerDuty event of a notification, a resolve when
+// the check recovered and a trigger otherwise.
+func (p *PagerDutyNotifier) event(n Notification) PagerDutyEvent {
+	event := PagerDutyEvent{
+		RoutingKey:  p.RoutingKey,
+		EventAction: PagerDutyResolve,
+		DedupKey:    PagerDutyDedupKey(n.Service, n.CheckID),
+	}
+	if n.Recovered() {
+		return event
+	}
+
+	source := p.Source
+	if source == "" {
+		source = n.Service
+	}
+	event.EventAction = PagerDutyTrigger
+	event.Payload = &PagerDutyPayload{
+		Summary:   truncate(fmt.Sprintf("%s: %s", n.Service, n.String()), maxPagerDutySummary),
+		Source:    source,
+		Severity:  PagerDutySeverity(n.Criticality, n.Status),
+		Component: n.CheckName,
+		Group:     n.Service,
+		Class:     n.CheckType,
+		CustomDetails: map[string]any{
+			"check_id":        n.CheckID,
+			"check_key":       n.CheckKey,
+			"criticality":     n.Criticality,
+			"tags":            n.Tags,
+			"previous_status": n.PreviousStatus,
+			"status":          n.Status,
+			"message":         n.Message,
+			"duration_ms":     n.Duration.Milliseconds(),
+			"overall_status":  n.OverallStatus,
+			"failing_checks":  n.FailingChecks,
+		},
+	}
+	if !n.FinishedAt.IsZero() {
+		event.Payload.Timestamp = n.FinishedAt.Format(time.RFC3339)
+	}
+	return event
+}
+
+// truncate shortens text to at most max bytes, ending it with "..."
+// when it is cut. it never cuts a rune in half.
+func truncate(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	end := max - len("...")
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return text[:end] + "..."
+}
@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// pagerDutyServer stubs the Events API, recording the events it is sent.
+func pagerDutyServer(t *testing.T) (*httptest.Server, *[]PagerDutyEvent) {
+	var events []PagerDutyEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event PagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decoding event: %v", err)
+		}
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &events
+}
+
+func TestPagerDutyNotifierTriggersAndResolves(t *testing.T) {
+	server, events := pagerDutyServer(t)
+	pagerDuty := NewPagerDutyNotifier("routing-key")
+	pagerDuty.URL = server.URL
+
+	failing := testNotification()
+	recovered := testNotification()
+	recovered.PreviousStatus, recovered.Status = StatusFail, StatusPass
+	for _, n := range []Notification{failing, recovered} {
+		if err := pagerDuty.Notify(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(*events) != 2 {
+		t.Fatalf("got %d events, want a trigger and a resolve", len(*events))
+	}
+	trigger, resolve := (*events)[0], (*events)[1]
+	dedupKey := "payments-api:" + failing.CheckID
+	if trigger.EventAction != PagerDutyTrigger || trigger.DedupKey != dedupKey || trigger.RoutingKey != "routing-key" {
+		t.Errorf("trigger = %+v", trigger)
+	}
+	payload := trigger.Payload
+	if payload == nil {
+		t.Fatal("trigger has no payload")
+	}
+	if payload.Summary != "payments-api: "+failing.String() || payload.Source != "payments-api" || payload.Severity != "critical" {
+		t.Errorf("payload = %+v", payload)
+	}
+	if payload.Component != "Postgres <main>" || payload.Class != "postgres" || payload.Timestamp != "2026-10-16T12:00:01Z" {
+		t.Errorf("payload = %+v", payload)
+	}
+	if resolve.EventAction != PagerDutyResolve || resolve.DedupKey != dedupKey || resolve.Payload != nil {
+		t.Errorf("resolve = %+v, want a resolve of %s without payload", resolve, dedupKey)
+	}
+}
+
+func TestPagerDutySeverity(t *testing.T) {
+	tests := []struct {
+		criticality, status, want string
+	}{
+		{criticality: "critical", status: StatusFail, want: "critical"},
+		{criticality: "", status: StatusFail, want: "critical"},
+		{criticality: "critical", status: StatusWarn, want: "warning"},
+		{criticality: "warning", status: StatusWarn, want: "warning"},
+		{criticality: "info", status: StatusWarn, want: "warning"},
+		{criticality: "info", status: StatusFail, want: "info"},
+	}
+	for _, tt := range tests {
+		if got := PagerDutySeverity(tt.criticality, tt.status); got != tt.want {
+			t.Errorf("PagerDutySeverity(%q, %q) = %q, want %q", tt.criticality, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestPagerDutyNotifierTruncatesSummary(t *testing.T) {
+	server, events := pagerDutyServer(t)
+	pagerDuty := NewPagerDutyNotifier("routing-key")
+	pagerDuty.URL = server.URL
+
+	n := testNotification()
+	n.Message = strings.Repeat("é", maxPagerDutySummary)
+	if err := pagerDuty.Notify(n); err != nil {
+		t.Fatal(err)
+	}
+	summary := (*events)[0].Payload.Summary
+	if len(summary) > maxPagerDutySummary || !strings.HasSuffix(summary, "é...") || !utf8.ValidString(summary) {
+		t.Errorf("summary of %d bytes ending in %q, want at most %d bytes of valid UTF-8 ending in an ellipsis", len(summary), summary[len(summary)-8:], maxPagerDutySummary)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		text string
+		max  int
+		want string
+	}{
+		{text: "short", max: 10, want: "short"},
+		{text: "exactly10!", max: 10, want: "exactly10!"},
+		{text: "a bit too long", max: 10, want: "a bit t..."},
+		{text: "ab€€€€", max: 9, want: "ab€..."},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.text, tt.max); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.text, tt.max, got, tt.want)
+		}
+	}
+}
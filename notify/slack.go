@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Colors of the Slack messages for every status, they match the
+// health check page.
+const (
+	ColorPass = "#3cb371"
+	ColorWarn = "#daa520"
+	ColorFail = "#b22222"
+)
+
+// SlackNotifier posts Block Kit messages to a Slack incoming webhook.
+type SlackNotifier struct {
+	// WebhookURL is the incoming webhook the messages are posted to.
+	WebhookURL string
+	// Channel, Username, IconEmoji and IconURL override the defaults
+	// of the webhook when set.
+	Channel   string
+	Username  string
+	IconEmoji string
+	IconURL   string
+	// Client is the http client used to post messages. a client with
+	// DefaultTimeout is used when it is nil.
+	Client *http.Client
+}
+
+// NewSlackNotifier creates a SlackNotifier posting to webhookURL.
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{
+		WebhookURL: webhookURL,
+		Client:     &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+type slackMessage struct {
+	Channel     string            `json:"channel,omitempty"`
+	Username    string            `json:"username,omitempty"`
+	IconEmoji   string            `json:"icon_emoji,omitempty"`
+	IconURL     string            `json:"icon_url,omitempty"`
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (s *SlackNotifier) Notify(n Notification) error {
+	body, err := json.Marshal(s.message(n))
+	if err != nil {
+		return fmt.Errorf("slack: encoding message: %w", err)
+	}
+	if err := post(context.Background(), s.Client, s.WebhookURL, "application/json", body, nil); err != nil {
+		return fmt.Errorf("slack: %w", err)
+	}
+	return nil
+}
+
+// message builds the Slack message of a notification.
+func (s *SlackNotifier) message(n Notification) slackMessage {
+	headline := fmt.Sprintf("*%s* is now *%s*", slackEscape(n.CheckName), StateName(n.Status))
+	blocks := []slackBlock{
+		{Type: "section", Text: &slackText{Type: "mrkdwn", Text: headline}},
+		{Type: "section", Text: &slackText{Type: "mrkdwn", Text: slackEscape(n.Message)}},
+		{Type: "section", Fields: []slackText{
+			{Type: "mrkdwn", Text: "*Service*\n" + slackEscape(n.Service)},
+			{Type: "mrkdwn", Text: "*Type*\n" + slackEscape(n.CheckType)},
+			{Type: "mrkdwn", Text: "*Criticality*\n" + slackEscape(n.Criticality)},
+			{Type: "mrkdwn", Text: "*Overall status*\n" + slackEscape(n.OverallStatus)},
+		}},
+	}
+	if len(n.FailingChecks) > 0 {
+		failing := make([]string, len(n.FailingChecks))
+		for i, name := range n.FailingChecks {
+			failing[i] = "• " + slackEscape(name)
+		}
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: "*Failing checks*\n" + strings.Join(failing, "\n")},
+		})
+	}
+	footer := fmt.Sprintf("was %s", StateName(n.PreviousStatus))
+	if n.PreviousStatusDuration > 0 {
+		footer += " for " + n.PreviousStatusDuration.Round(time.Millisecond).String()
+	}
+	footer += " · checked in " + n.Duration.Round(time.Millisecond).String()
+	blocks = append(blocks, slackBlock{
+		Type:     "context",
+		Elements: []slackText{{Type: "mrkdwn", Text: footer}},
+	})
+
+	return slackMessage{
+		Channel:   s.Channel,
+		Username:  s.Username,
+		IconEmoji: s.IconEmoji,
+		IconURL:   s.IconURL,
+		Text:      slackEscape(n.String()),
+		Attachments: []slackAttachment{
+			{Color: statusColor(n.Status), Blocks: blocks},
+		},
+	}
+}
+
+// statusColor returns the color of a check status.
+func statusColor(status string) string {
+	switch status {
+	case StatusFail:
+		return ColorFail
+	case StatusWarn:
+		return ColorWarn
+	default:
+		return ColorPass
+	}
+}
+
+// slackEscape escapes the characters Slack treats as control
+// characters in mrkdwn text.
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
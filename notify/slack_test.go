@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testNotification is a check moving from healthy to failing.
+func testNotification() Notification {
+	return Notification{
+		Service:                "payments-api",
+		CheckID:                "0d5b1a4c-3c6f-5f0a-9c44-0bfa5e1c2d10",
+		CheckKey:               "postgres/main",
+		CheckName:              "Postgres <main>",
+		CheckType:              "postgres",
+		Criticality:            "critical",
+		Tags:                   []string{"db"},
+		PreviousStatus:         StatusPass,
+		Status:                 StatusFail,
+		PreviousStatusDuration: 90 * time.Minute,
+		Message:                "connection refused",
+		StartedAt:              time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		FinishedAt:             time.Date(2026, 10, 16, 12, 0, 1, 0, time.UTC),
+		Duration:               time.Second,
+		OverallStatus:          "error",
+		FailingChecks:          []string{"Postgres <main>", "Redis"},
+	}
+}
+
+func TestSlackNotifierPostsBlockKitMessage(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %s with content type %q, want a json POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, _ = io.ReadAll(r.Body)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	slack := NewSlackNotifier(server.URL)
+	slack.Channel = "#alerts"
+	slack.Username = "allgood"
+	slack.IconEmoji = ":rotating_light:"
+	if err := slack.Notify(testNotification()); err != nil {
+		t.Fatal(err)
+	}
+
+	var message slackMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		t.Fatalf("decoding %s: %v", body, err)
+	}
+	if message.Channel != "#alerts" || message.Username != "allgood" || message.IconEmoji != ":rotating_light:" {
+		t.Errorf("message overrides = %q, %q, %q", message.Channel, message.Username, message.IconEmoji)
+	}
+	if len(message.Attachments) != 1 || message.Attachments[0].Color != ColorFail {
+		t.Fatalf("attachments = %+v, want one colored %s", message.Attachments, ColorFail)
+	}
+	blocks := message.Attachments[0].Blocks
+	if blocks[0].Text.Text != "*Postgres &lt;main&gt;* is now *failing*" {
+		t.Errorf("headline = %q", blocks[0].Text.Text)
+	}
+	var failing, footer string
+	for _, block := range blocks {
+		if block.Text != nil && strings.HasPrefix(block.Text.Text, "*Failing checks*") {
+			failing = block.Text.Text
+		}
+		if block.Type == "context" {
+			footer = block.Elements[0].Text
+		}
+	}
+	if failing != "*Failing checks*\n• Postgres &lt;main&gt;\n• Redis" {
+		t.Errorf("failing checks block = %q", failing)
+	}
+	if footer != "was healthy for 1h30m0s · checked in 1s" {
+		t.Errorf("footer = %q", footer)
+	}
+}
+
+func TestSlackNotifierColors(t *testing.T) {
+	tests := []struct {
+		status string
+		want   string
+	}{
+		{status: StatusPass, want: ColorPass},
+		{status: StatusWarn, want: ColorWarn},
+		{status: StatusFail, want: ColorFail},
+	}
+	for _, tt := range tests {
+		n := testNotification()
+		n.Status = tt.status
+		if got := (&SlackNotifier{}).message(n).Attachments[0].Color; got != tt.want {
+			t.Errorf("color of %s = %s, want %s", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestSlackNotifierNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := NewSlackNotifier(server.URL).Notify(testNotification())
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Notify() = %v, want a *StatusError", err)
+	}
+	if statusErr.StatusCode != http.StatusBadRequest || !strings.Contains(statusErr.Body, "invalid_payload") {
+		t.Fatalf("StatusError = %+v, want the 400 and its body", statusErr)
+	}
+}
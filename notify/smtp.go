@@ -0,0 +1,238 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saintmalik/allgood/internal/models"
+	"github.com/saintmalik/allgood/internal/views"
+)
+
+// SMTPSecurity is how the connection to an SMTP server is secured.
+type SMTPSecurity int
+
+const (
+	// SMTPStartTLS upgrades the connection with STARTTLS and fails if
+	// the server doesn't support it. it is the default.
+	SMTPStartTLS SMTPSecurity = iota
+	// SMTPImplicitTLS connects over TLS right away, usually on port
+	// 465.
+	SMTPImplicitTLS
+	// SMTPInsecure doesn't secure the connection, only use it with a
+	// local relay.
+	SMTPInsecure
+)
+
+// SMTPNotifier sends multipart emails with a plain text and an html
+// body through an SMTP server.
+type SMTPNotifier struct {
+	// Host and Port of the SMTP server.
+	Host string
+	Port int
+	// Username and Password authenticate with PLAIN auth when
+	// Username is set.
+	Username string
+	Password string
+	// From is the sender address.
+	From string
+	// To are the recipient addresses.
+	To []string
+	// Security is how the connection is secured.
+	Security SMTPSecurity
+	// TLSConfig is used for STARTTLS and implicit TLS, a config with
+	// Host as ServerName is used when it is nil.
+	TLSConfig *tls.Config
+	// Timeout bounds sending a whole email, DefaultTimeout when zero.
+	Timeout time.Duration
+}
+
+// NewSMTPNotifier creates an SMTPNotifier sending from from to every
+// address in to through host:port with STARTTLS.
+func NewSMTPNotifier(host string, port int, username, password, from string, to ...string) *SMTPNotifier {
+	return &SMTPNotifier{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		From:     from,
+		To:       to,
+	}
+}
+
+func (s *SMTPNotifier) Notify(n Notification) error {
+	if len(s.To) == 0 {
+		return errors.New("smtp: no recipients")
+	}
+	message, err := s.message(n)
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	if err := s.send(message); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	return nil
+}
+
+// send delivers message to every recipient.
+func (s *SMTPNotifier) send(message []byte) error {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	tlsConfig := s.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: s.Host}
+	}
+
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	var err error
+	if s.Security == SMTPImplicitTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("connecting to %s: %w", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("greeting %s: %w", addr, err)
+	}
+	defer client.Close()
+
+	if s.Security == SMTPStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s doesn't support STARTTLS", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("starting tls: %w", err)
+		}
+	}
+	if s.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return fmt.Errorf("authenticating: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.From); err != nil {
+		return fmt.Errorf("setting sender: %w", err)
+	}
+	for _, to := range s.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("adding recipient %s: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("starting data: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("writing message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("sending message: %w", err)
+	}
+	return client.Quit()
+}
+
+// message builds the multipart/alternative email of a notification.
+func (s *SMTPNotifier) message(n Notification) ([]byte, error) {
+	var html bytes.Buffer
+	result := models.Result{
+		Id:          n.CheckID,
+		Key:         n.CheckKey,
+		Name:        n.CheckName,
+		Success:     n.Status == StatusPass,
+		Message:     n.Message,
+		Status:      models.Status(n.Status),
+		Criticality: n.Criticality,
+		Tags:        n.Tags,
+		StartedAt:   n.StartedAt,
+		FinishedAt:  n.FinishedAt,
+		Duration:    n.Duration,
+	}
+	err := views.HealthCheckEmail(n.Service, []models.Result{result}, n.OverallStatus).Render(context.Background(), &html)
+	if err != nil {
+		return nil, fmt.Errorf("rendering html: %w", err)
+	}
+
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+	subject := fmt.Sprintf("[%s] %s is now %s", n.Service, n.CheckName, StateName(n.Status))
+
+	var msg bytes.Buffer
+	header := textproto.MIMEHeader{}
+	header.Set("From", s.From)
+	header.Set("To", strings.Join(s.To, ", "))
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("MIME-Version", "1.0")
+	header.Set("Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+	for _, name := range []string{"From", "To", "Subject", "Date", "MIME-Version", "Content-Type"} {
+		fmt.Fprintf(&msg, "%s: %s\r\n", name, header.Get(name))
+	}
+	msg.WriteString("\r\n")
+
+	writePart(&msg, boundary, "text/plain; charset=utf-8", []byte(plainText(n)))
+	writePart(&msg, boundary, "text/html; charset=utf-8", html.Bytes())
+	fmt.Fprintf(&msg, "--%s--\r\n", boundary)
+	return msg.Bytes(), nil
+}
+
+// writePart writes a quoted-printable part of a multipart message.
+func writePart(msg *bytes.Buffer, boundary, contentType string, body []byte) {
+	fmt.Fprintf(msg, "--%s\r\n", boundary)
+	fmt.Fprintf(msg, "Content-Type: %s\r\n", contentType)
+	msg.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	qp := quotedprintable.NewWriter(msg)
+	qp.Write(body)
+	qp.Close()
+	msg.WriteString("\r\n")
+}
+
+// plainText formats a notification as the plain text body of an email.
+func plainText(n Notification) string {
+	var b strings.Builder
+	b.WriteString(n.String() + "\n\n")
+	fmt.Fprintf(&b, "Service: %s\n", n.Service)
+	fmt.Fprintf(&b, "Type: %s\n", n.CheckType)
+	fmt.Fprintf(&b, "Criticality: %s\n", n.Criticality)
+	fmt.Fprintf(&b, "Overall status: %s\n", n.OverallStatus)
+	fmt.Fprintf(&b, "Checked at: %s in %s\n", n.FinishedAt.Format(time.RFC3339), n.Duration.Round(time.Millisecond))
+	if len(n.FailingChecks) > 0 {
+		b.WriteString("\nFailing checks:\n")
+		for _, name := range n.FailingChecks {
+			fmt.Fprintf(&b, "- %s\n", name)
+		}
+	}
+	return b.String()
+}
+
+// randomBoundary returns a random multipart boundary.
+func randomBoundary() (string, error) {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", fmt.Errorf("generating boundary: %w", err)
+	}
+	return "allgood-" + hex.EncodeToString(buf[:]), nil
+}
@@ -0,0 +1,344 @@
+package notify
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpServer is a fake SMTP server accepting a single email.
+type smtpServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	// startTLS advertises STARTTLS.
+	startTLS bool
+
+	mu sync.Mutex
+	// auth is the decoded AUTH PLAIN response and authTLS whether it was
+	// sent over TLS.
+	auth    string
+	authTLS bool
+	from    string
+	to      []string
+	data    string
+	done    chan struct{}
+}
+
+// newSMTPServer starts a fake SMTP server on a local port, implicitTLS
+// makes it speak TLS right away.
+func newSMTPServer(t *testing.T, implicitTLS, startTLS bool) *smtpServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpServer{listener: listener, tlsConfig: serverTLSConfig(t), startTLS: startTLS, done: make(chan struct{})}
+	if implicitTLS {
+		s.listener = tls.NewListener(listener, s.tlsConfig)
+	}
+	t.Cleanup(func() { s.listener.Close() })
+	go s.serve(t)
+	return s
+}
+
+func (s *smtpServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpServer) serve(t *testing.T) {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	_, secure := conn.(*tls.Conn)
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			extensions := []string{"250-fake", "250-8BITMIME"}
+			if s.startTLS && !secure {
+				extensions = append(extensions, "250-STARTTLS")
+			}
+			extensions = append(extensions, "250 AUTH PLAIN")
+			text.PrintfLine("%s", strings.Join(extensions, "\r\n"))
+		case "STARTTLS":
+			text.PrintfLine("220 ready")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, secure = tlsConn, true
+			text = textproto.NewConn(conn)
+		case "AUTH":
+			mechanism, response, _ := strings.Cut(arg, " ")
+			decoded, err := base64.StdEncoding.DecodeString(response)
+			if mechanism != "PLAIN" || err != nil {
+				text.PrintfLine("535 bad auth")
+				continue
+			}
+			s.mu.Lock()
+			s.auth, s.authTLS = string(decoded), secure
+			s.mu.Unlock()
+			text.PrintfLine("235 ok")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = smtpAddress(arg)
+			s.mu.Unlock()
+			text.PrintfLine("250 ok")
+		case "RCPT":
+			s.mu.Lock()
+			s.to = append(s.to, smtpAddress(arg))
+			s.mu.Unlock()
+			text.PrintfLine("250 ok")
+		case "DATA":
+			text.PrintfLine("354 go ahead")
+			data, err := io.ReadAll(text.DotReader())
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			text.PrintfLine("250 queued")
+		case "QUIT":
+			text.PrintfLine("221 bye")
+			return
+		default:
+			text.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+// smtpAddress returns the address of a MAIL or RCPT argument, e.g.
+// "FROM:<a@example.com> BODY=8BITMIME".
+func smtpAddress(arg string) string {
+	_, address, _ := strings.Cut(arg, "<")
+	address, _, _ = strings.Cut(address, ">")
+	return address
+}
+
+// wait waits for the server to have handled its connection.
+func (s *smtpServer) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-s.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the smtp server never finished")
+	}
+}
+
+// serverTLSConfig returns the TLS config of a server with a self signed
+// certificate for 127.0.0.1.
+func serverTLSConfig(t *testing.T) *tls.Config {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+}
+
+// clientTLSConfig returns a TLS config trusting the certificate of s.
+func (s *smtpServer) clientTLSConfig(t *testing.T) *tls.Config {
+	t.Helper()
+	cert, err := x509.ParseCertificate(s.tlsConfig.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	return &tls.Config{RootCAs: roots, ServerName: "127.0.0.1"}
+}
+
+func TestSMTPNotifierSecurity(t *testing.T) {
+	tests := []struct {
+		name        string
+		security    SMTPSecurity
+		implicitTLS bool
+		startTLS    bool
+		wantTLS     bool
+	}{
+		{name: "starttls", security: SMTPStartTLS, startTLS: true, wantTLS: true},
+		{name: "implicit tls", security: SMTPImplicitTLS, implicitTLS: true, wantTLS: true},
+		{name: "insecure", security: SMTPInsecure, startTLS: true, wantTLS: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newSMTPServer(t, tt.implicitTLS, tt.startTLS)
+			smtp := NewSMTPNotifier("127.0.0.1", server.port(), "alerts", "s3cret", "allgood@example.com", "oncall@example.com", "team@example.com")
+			smtp.Security = tt.security
+			smtp.TLSConfig = server.clientTLSConfig(t)
+
+			if err := smtp.Notify(testNotification()); err != nil {
+				t.Fatal(err)
+			}
+			server.wait(t)
+
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			if server.auth != "\x00alerts\x00s3cret" {
+				t.Errorf("AUTH PLAIN = %q, want the username and password", server.auth)
+			}
+			if server.authTLS != tt.wantTLS {
+				t.Errorf("authenticated over tls = %t, want %t", server.authTLS, tt.wantTLS)
+			}
+			if server.from != "allgood@example.com" || strings.Join(server.to, ",") != "oncall@example.com,team@example.com" {
+				t.Errorf("sent from %s to %v", server.from, server.to)
+			}
+			if server.data == "" {
+				t.Error("no message was sent")
+			}
+		})
+	}
+}
+
+func TestSMTPNotifierRequiresStartTLS(t *testing.T) {
+	server := newSMTPServer(t, false, false)
+	smtp := NewSMTPNotifier("127.0.0.1", server.port(), "alerts", "s3cret", "allgood@example.com", "oncall@example.com")
+	smtp.TLSConfig = server.clientTLSConfig(t)
+
+	err := smtp.Notify(testNotification())
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("Notify() = %v, want an error about STARTTLS", err)
+	}
+	server.wait(t)
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.auth != "" || server.data != "" {
+		t.Fatal("authenticated or sent a message over an insecure connection")
+	}
+}
+
+func TestSMTPNotifierWithoutAuth(t *testing.T) {
+	server := newSMTPServer(t, false, false)
+	smtp := NewSMTPNotifier("127.0.0.1", server.port(), "", "", "allgood@example.com", "oncall@example.com")
+	smtp.Security = SMTPInsecure
+
+	if err := smtp.Notify(testNotification()); err != nil {
+		t.Fatal(err)
+	}
+	server.wait(t)
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.auth != "" {
+		t.Fatalf("authenticated without a username: %q", server.auth)
+	}
+}
+
+func TestSMTPNotifierNoRecipients(t *testing.T) {
+	if err := (&SMTPNotifier{Host: "127.0.0.1", Port: 1}).Notify(testNotification()); err == nil {
+		t.Fatal("Notify() without recipients = nil, want an error")
+	}
+}
+
+func TestSMTPMessageParts(t *testing.T) {
+	smtp := NewSMTPNotifier("127.0.0.1", 25, "", "", "allgood@example.com", "oncall@example.com", "team@example.com")
+	n := testNotification()
+	raw, err := smtp.message(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "[payments-api] Postgres <main> is now failing" {
+		t.Errorf("Subject = %q", subject)
+	}
+	if msg.Header.Get("To") != "oncall@example.com, team@example.com" || msg.Header.Get("From") != "allgood@example.com" {
+		t.Errorf("From %q To %q", msg.Header.Get("From"), msg.Header.Get("To"))
+	}
+	if _, err := msg.Header.Date(); err != nil {
+		t.Errorf("Date: %v", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v), want multipart/alternative", msg.Header.Get("Content-Type"), err)
+	}
+	parts := map[string]string{}
+	var order []string
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		// NextPart decodes quoted-printable parts and drops the header.
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contentType := part.Header.Get("Content-Type")
+		order = append(order, contentType)
+		parts[contentType] = string(body)
+	}
+
+	if strings.Join(order, ",") != "text/plain; charset=utf-8,text/html; charset=utf-8" {
+		t.Fatalf("parts = %v, want plain text then html", order)
+	}
+	plain := parts["text/plain; charset=utf-8"]
+	for _, want := range []string{n.String(), "Service: payments-api", "Overall status: error", "- Redis"} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("plain text part doesn't contain %q:\n%s", want, plain)
+		}
+	}
+	html := parts["text/html; charset=utf-8"]
+	for _, want := range []string{"<html", "Postgres &lt;main&gt;", "connection refused"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("html part doesn't contain %q:\n%s", want, html)
+		}
+	}
+	for _, line := range strings.Split(string(raw), "\r\n") {
+		if len(line) > 998 {
+			t.Fatalf("line of %d characters, the limit is 998", len(line))
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultTelegramAPIURL is the base url of the Telegram Bot API.
+const DefaultTelegramAPIURL = "https://api.telegram.org"
+
+// TelegramNotifier sends messages to a Telegram chat through the Bot
+// API sendMessage method.
+type TelegramNotifier struct {
+	// BotToken is the token of the bot sending the messages.
+	BotToken string
+	// ChatID is the id or @username of the chat the messages are
+	// sent to.
+	ChatID string
+	// APIURL overrides DefaultTelegramAPIURL when set, e.g. to send
+	// messages to a local stub.
+	APIURL string
+	// SilentRecoveries sends the messages of checks recovering
+	// without a sound.
+	SilentRecoveries bool
+	// Client is the http client used to send messages. a client with
+	// DefaultTimeout is used when it is nil.
+	Client *http.Client
+}
+
+// NewTelegramNotifier creates a TelegramNotifier sending messages to
+// chatID as the bot with botToken.
+func NewTelegramNotifier(botToken, chatID string) *TelegramNotifier {
+	return &TelegramNotifier{
+		BotToken: botToken,
+		ChatID:   chatID,
+		Client:   &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+type telegramMessage struct {
+	ChatID              string `json:"chat_id"`
+	Text                string `json:"text"`
+	ParseMode           string `json:"parse_mode"`
+	DisableNotification bool   `json:"disable_notification,omitempty"`
+}
+
+func (t *TelegramNotifier) Notify(n Notification) error {
+	body, err := json.Marshal(telegramMessage{
+		ChatID:              t.ChatID,
+		Text:                telegramText(n),
+		ParseMode:           "MarkdownV2",
+		DisableNotification: t.SilentRecoveries && n.Recovered(),
+	})
+	if err != nil {
+		return fmt.Errorf("telegram: encoding message: %w", err)
+	}
+
+	apiURL := t.APIURL
+	if apiURL == "" {
+		apiURL = DefaultTelegramAPIURL
+	}
+	url := strings.TrimSuffix(apiURL, "/") + "/bot" + t.BotToken + "/sendMessage"
+	if err := post(context.Background(), t.Client, url, "application/json", body, nil); err != nil {
+		return fmt.Errorf("telegram: %w", err)
+	}
+	return nil
+}
+
+// telegramText formats a notification as MarkdownV2.
+func telegramText(n Notification) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s *%s* is now *%s*\n", statusEmoji(n.Status), telegramEscape(n.CheckName), StateName(n.Status))
+	fmt.Fprintf(&b, "%s\n\n", telegramEscape(n.Message))
+
+	details := fmt.Sprintf("%s · %s · was %s", n.Service, n.CheckType, StateName(n.PreviousStatus))
+	if n.PreviousStatusDuration > 0 {
+		details += " for " + n.PreviousStatusDuration.Round(time.Millisecond).String()
+	}
+	fmt.Fprintf(&b, "_%s_", telegramEscape(details))
+
+	if len(n.FailingChecks) > 0 {
+		b.WriteString("\n\n*Failing checks*")
+		for _, name := range n.FailingChecks {
+			fmt.Fprintf(&b, "\n• %s", telegramEscape(name))
+		}
+	}
+	return b.String()
+}
+
+// statusEmoji returns the emoji of a check status.
+func statusEmoji(status string) string {
+	switch status {
+	case StatusFail:
+		return "🔴"
+	case StatusWarn:
+		return "⚠️"
+	default:
+		return "✅"
+	}
+}
+
+// telegramEscaper escapes every character reserved by MarkdownV2.
+var telegramEscaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+	"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+// telegramEscape escapes text for MarkdownV2.
+func telegramEscape(text string) string {
+	return telegramEscaper.Replace(text)
+}
@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// telegramServer stubs the Bot API, recording the messages it is sent.
+func telegramServer(t *testing.T, status int) (*httptest.Server, *[]telegramMessage) {
+	var messages []telegramMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bot123:secret/sendMessage" {
+			t.Errorf("path = %q, want the sendMessage method of the bot", r.URL.Path)
+		}
+		var message telegramMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+		messages = append(messages, message)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &messages
+}
+
+func TestTelegramNotifierEscapesMarkdown(t *testing.T) {
+	server, messages := telegramServer(t, http.StatusOK)
+	telegram := NewTelegramNotifier("123:secret", "@alerts")
+	telegram.APIURL = server.URL + "/"
+
+	n := testNotification()
+	n.CheckName = "db_main (primary)"
+	n.Message = "dial tcp 10.0.0.1:5432: i/o timeout! [retry=3]"
+	n.FailingChecks = []string{"db_main (primary)", "cache-1"}
+	if err := telegram.Notify(n); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*messages) != 1 {
+		t.Fatalf("got %d messages, want 1", len(*messages))
+	}
+	message := (*messages)[0]
+	if message.ChatID != "@alerts" || message.ParseMode != "MarkdownV2" || message.DisableNotification {
+		t.Errorf("message = %+v, want a MarkdownV2 message to @alerts with a sound", message)
+	}
+	want := "🔴 *db\\_main \\(primary\\)* is now *failing*\n" +
+		"dial tcp 10\\.0\\.0\\.1:5432: i/o timeout\\! \\[retry\\=3\\]\n\n" +
+		"_payments\\-api · postgres · was healthy for 1h30m0s_\n\n" +
+		"*Failing checks*\n• db\\_main \\(primary\\)\n• cache\\-1"
+	if message.Text != want {
+		t.Errorf("text =\n%s\nwant\n%s", message.Text, want)
+	}
+}
+
+func TestTelegramNotifierSilentRecoveries(t *testing.T) {
+	failing := testNotification()
+	recovered := testNotification()
+	recovered.PreviousStatus, recovered.Status = StatusFail, StatusPass
+
+	tests := []struct {
+		name   string
+		silent bool
+		n      Notification
+		want   bool
+	}{
+		{name: "failure", silent: true, n: failing, want: false},
+		{name: "recovery", silent: true, n: recovered, want: true},
+		{name: "recovery with sound", silent: false, n: recovered, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, messages := telegramServer(t, http.StatusOK)
+			telegram := NewTelegramNotifier("123:secret", "42")
+			telegram.APIURL = server.URL
+			telegram.SilentRecoveries = tt.silent
+			if err := telegram.Notify(tt.n); err != nil {
+				t.Fatal(err)
+			}
+			if got := (*messages)[0].DisableNotification; got != tt.want {
+				t.Errorf("disable_notification = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTelegramNotifierErrorsHideTheToken(t *testing.T) {
+	server, _ := telegramServer(t, http.StatusOK)
+	server.Close()
+
+	telegram := NewTelegramNotifier("123:secret", "42")
+	telegram.APIURL = server.URL
+	err := telegram.Notify(testNotification())
+	if err == nil {
+		t.Fatal("Notify() = nil, want an error from the closed server")
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("Notify() = %q, leaks the bot token", err)
+	}
+}
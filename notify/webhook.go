@@ -0,0 +1,224 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"text/template"
+	"time"
+)
+
+// Headers set on every webhook request.
+const (
+	// WebhookSignatureHeader holds "sha256=" followed by the hex HMAC
+	// SHA256 of the timestamp, a dot and the body, keyed by the secret.
+	WebhookSignatureHeader = "X-Allgood-Signature"
+	// WebhookTimestampHeader holds the unix time the request was
+	// signed at, receivers should reject old timestamps.
+	WebhookTimestampHeader = "X-Allgood-Timestamp"
+)
+
+const (
+	// DefaultWebhookRetries is the number of retries of a failed
+	// delivery when none is set.
+	DefaultWebhookRetries = 3
+	// DefaultWebhookBackoff is the delay before the first retry when
+	// none is set, it doubles after every retry.
+	DefaultWebhookBackoff = 500 * time.Millisecond
+	// DefaultWebhookMaxElapsed is how long a delivery and its retries
+	// may take when no limit is set.
+	DefaultWebhookMaxElapsed = 30 * time.Second
+)
+
+// WebhookNotifier posts a JSON event to any url, signed with HMAC
+// SHA256 when a secret is set.
+type WebhookNotifier struct {
+	// URL is where the events are posted.
+	URL string
+	// Secret is the key of the request signature, requests are not
+	// signed when it is empty.
+	Secret string
+	// Headers are added to every request.
+	Headers map[string]string
+	// Template renders the body from the Notification instead of the
+	// WebhookEvent JSON when set.
+	Template *template.Template
+	// ContentType of the body, application/json when empty.
+	ContentType string
+	// MaxRetries is the number of retries of a failed delivery,
+	// DefaultWebhookRetries when zero and none when negative.
+	MaxRetries int
+	// Backoff is the delay before the first retry,
+	// DefaultWebhookBackoff when zero. it doubles after every retry.
+	Backoff time.Duration
+	// MaxElapsed is how long a delivery and its retries may take in
+	// total, DefaultWebhookMaxElapsed when zero. no retry is made once
+	// its backoff would run past it.
+	MaxElapsed time.Duration
+	// Client is the http client used to post events. a client with
+	// DefaultTimeout is used when it is nil.
+	Client *http.Client
+}
+
+// NewWebhookNotifier creates a WebhookNotifier posting to url and
+// signing requests with secret.
+func NewWebhookNotifier(url, secret string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Secret: secret,
+		Client: &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+// WebhookEvent is the JSON body posted by a WebhookNotifier.
+type WebhookEvent struct {
+	Event                    string    `json:"event"`
+	Service                  string    `json:"service"`
+	CheckID                  string    `json:"checkId"`
+	CheckKey                 string    `json:"checkKey"`
+	CheckName                string    `json:"checkName"`
+	CheckType                string    `json:"checkType"`
+	Criticality              string    `json:"criticality"`
+	Tags                     []string  `json:"tags"`
+	PreviousStatus           string    `json:"previousStatus"`
+	Status                   string    `json:"status"`
+	PreviousStatusDurationMs int64     `json:"previousStatusDurationMs"`
+	Message                  string    `json:"message"`
+	StartedAt                time.Time `json:"startedAt"`
+	FinishedAt               time.Time `json:"finishedAt"`
+	DurationMs               int64     `json:"durationMs"`
+	OverallStatus            string    `json:"overallStatus"`
+	FailingChecks            []string  `json:"failingChecks"`
+}
+
+// NewWebhookEvent creates the WebhookEvent of a notification.
+func NewWebhookEvent(n Notification) WebhookEvent {
+	return WebhookEvent{
+		Event:                    "check.status_changed",
+		Service:                  n.Service,
+		CheckID:                  n.CheckID,
+		CheckKey:                 n.CheckKey,
+		CheckName:                n.CheckName,
+		CheckType:                n.CheckType,
+		Criticality:              n.Criticality,
+		Tags:                     n.Tags,
+		PreviousStatus:           n.PreviousStatus,
+		Status:                   n.Status,
+		PreviousStatusDurationMs: n.PreviousStatusDuration.Milliseconds(),
+		Message:                  n.Message,
+		StartedAt:                n.StartedAt,
+		FinishedAt:               n.FinishedAt,
+		DurationMs:               n.Duration.Milliseconds(),
+		OverallStatus:            n.OverallStatus,
+		FailingChecks:            n.FailingChecks,
+	}
+}
+
+// Sign returns the value of WebhookSignatureHeader for body signed at
+// timestamp with secret. receivers can use it to verify requests.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (w *WebhookNotifier) Notify(n Notification) error {
+	body, err := w.body(n)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+
+	retries := w.MaxRetries
+	if retries == 0 {
+		retries = DefaultWebhookRetries
+	}
+	backoff := w.Backoff
+	if backoff <= 0 {
+		backoff = DefaultWebhookBackoff
+	}
+	maxElapsed := w.MaxElapsed
+	if maxElapsed <= 0 {
+		maxElapsed = DefaultWebhookMaxElapsed
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), maxElapsed)
+	defer cancel()
+
+	for attempt := 0; ; attempt++ {
+		err = w.deliver(ctx, body)
+		if err == nil || attempt >= retries || !retryable(err) {
+			break
+		}
+		if deadline, _ := ctx.Deadline(); time.Until(deadline) < backoff {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return nil
+}
+
+// body renders the request body of a notification.
+func (w *WebhookNotifier) body(n Notification) ([]byte, error) {
+	if w.Template == nil {
+		body, err := json.Marshal(NewWebhookEvent(n))
+		if err != nil {
+			return nil, fmt.Errorf("encoding event: %w", err)
+		}
+		return body, nil
+	}
+	var buf bytes.Buffer
+	if err := w.Template.Execute(&buf, n); err != nil {
+		return nil, fmt.Errorf("rendering template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// deliver makes a single signed delivery attempt.
+func (w *WebhookNotifier) deliver(ctx context.Context, body []byte) error {
+	headers := make(map[string]string, len(w.Headers)+2)
+	for name, value := range w.Headers {
+		headers[name] = value
+	}
+	if w.Secret != "" {
+		timestamp := time.Now().Unix()
+		headers[WebhookTimestampHeader] = strconv.FormatInt(timestamp, 10)
+		headers[WebhookSignatureHeader] = Sign(w.Secret, timestamp, body)
+	}
+
+	contentType := w.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	return post(ctx, w.Client, w.URL, contentType, body, headers)
+}
+
+// retryable reports whether a failed delivery may succeed when retried,
+// which is the case unless the receiver rejected it with a 4xx other
+// than 408 or 429.
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return true
+	}
+	switch {
+	case statusErr.StatusCode == http.StatusRequestTimeout,
+		statusErr.StatusCode == http.StatusTooManyRequests:
+		return true
+	case statusErr.StatusCode >= 400 && statusErr.StatusCode < 500:
+		return false
+	default:
+		return true
+	}
+}
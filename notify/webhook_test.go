@@ -0,0 +1,179 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestWebhookNotifierSignsEvents(t *testing.T) {
+	var header http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhookNotifier(server.URL, "s3cret")
+	webhook.Headers = map[string]string{"Authorization": "Bearer abc", "X-Team": "payments"}
+	if err := webhook.Notify(testNotification()); err != nil {
+		t.Fatal(err)
+	}
+
+	timestamp, err := strconv.ParseInt(header.Get(WebhookTimestampHeader), 10, 64)
+	if err != nil || time.Since(time.Unix(timestamp, 0)) > time.Minute {
+		t.Fatalf("timestamp header = %q, want the current unix time", header.Get(WebhookTimestampHeader))
+	}
+	if got, want := header.Get(WebhookSignatureHeader), Sign("s3cret", timestamp, body); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	if header.Get("Authorization") != "Bearer abc" || header.Get("X-Team") != "payments" {
+		t.Errorf("custom headers missing from %v", header)
+	}
+	if header.Get("Content-Type") != "application/json" {
+		t.Errorf("content type = %q, want application/json", header.Get("Content-Type"))
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("decoding %s: %v", body, err)
+	}
+	if event.Event != "check.status_changed" || event.CheckKey != "postgres/main" || event.Status != StatusFail || event.PreviousStatusDurationMs != (90*time.Minute).Milliseconds() {
+		t.Errorf("event = %+v", event)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// computed with: printf '1700000000.{"a":1}' | openssl dgst -sha256 -hmac key
+	want := "sha256=a438e398bfafc57e4396bb7fc2304422f0f768e965d073ca313cb52e22e6ad03"
+	if got := Sign("key", 1700000000, []byte(`{"a":1}`)); got != want {
+		t.Errorf("Sign() = %q, want %q", got, want)
+	}
+}
+
+func TestWebhookNotifierUnsignedWithoutSecret(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier(server.URL, "").Notify(testNotification()); err != nil {
+		t.Fatal(err)
+	}
+	if header.Get(WebhookSignatureHeader) != "" || header.Get(WebhookTimestampHeader) != "" {
+		t.Errorf("headers = %v, want no signature", header)
+	}
+}
+
+func TestWebhookNotifierTemplate(t *testing.T) {
+	var contentType string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhookNotifier(server.URL, "")
+	webhook.Template = template.Must(template.New("").Parse(`{{.CheckName}} is {{.Status}}: {{.Message}}`))
+	webhook.ContentType = "text/plain"
+	if err := webhook.Notify(testNotification()); err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "text/plain" || string(body) != "Postgres <main> is fail: connection refused" {
+		t.Errorf("got %q with content type %q", body, contentType)
+	}
+}
+
+func TestWebhookNotifierRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		maxRetries int
+		want       int32
+	}{
+		{name: "server error", status: http.StatusBadGateway, maxRetries: 2, want: 3},
+		{name: "request timeout", status: http.StatusRequestTimeout, maxRetries: 2, want: 3},
+		{name: "too many requests", status: http.StatusTooManyRequests, maxRetries: 2, want: 3},
+		{name: "default retries", status: http.StatusServiceUnavailable, want: DefaultWebhookRetries + 1},
+		{name: "client error", status: http.StatusBadRequest, maxRetries: 2, want: 1},
+		{name: "unauthorized", status: http.StatusUnauthorized, maxRetries: 2, want: 1},
+		{name: "no retries", status: http.StatusBadGateway, maxRetries: -1, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			webhook := NewWebhookNotifier(server.URL, "")
+			webhook.MaxRetries = tt.maxRetries
+			webhook.Backoff = time.Millisecond
+			err := webhook.Notify(testNotification())
+
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+				t.Errorf("Notify() = %v, want status %d", err, tt.status)
+			}
+			if got := requests.Load(); got != tt.want {
+				t.Errorf("got %d requests, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierRetriesUntilDelivered(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	webhook := NewWebhookNotifier(server.URL, "")
+	webhook.Backoff = time.Millisecond
+	if err := webhook.Notify(testNotification()); err != nil {
+		t.Fatalf("Notify() = %v, want the third attempt delivered", err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+}
+
+func TestWebhookNotifierMaxElapsed(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhookNotifier(server.URL, "")
+	webhook.MaxRetries = 10
+	webhook.Backoff = 40 * time.Millisecond
+	webhook.MaxElapsed = 100 * time.Millisecond
+
+	begun := time.Now()
+	if err := webhook.Notify(testNotification()); err == nil {
+		t.Fatal("Notify() = nil, want the last delivery error")
+	}
+	if elapsed := time.Since(begun); elapsed > 100*time.Millisecond {
+		t.Errorf("Notify() took %s, want at most MaxElapsed", elapsed)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("got %d requests, want 2 before the 80ms backoff runs past MaxElapsed", got)
+	}
+}
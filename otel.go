@@ -0,0 +1,160 @@
+package allgood
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/saintmalik/allgood/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// instrumentationName is the OpenTelemetry instrumentation scope of the
+// Engine.
+const instrumentationName = "github.com/saintmalik/allgood"
+
+// OpenTelemetry attribute keys of check spans and metrics.
+const (
+	AttributeCheckID      = attribute.Key("allgood.check.id")
+	AttributeCheckKey     = attribute.Key("allgood.check.key")
+	AttributeCheckName    = attribute.Key("allgood.check.name")
+	AttributeCheckType    = attribute.Key("allgood.check.type")
+	AttributeCheckSuccess = attribute.Key("allgood.check.success")
+	AttributeCheckMessage = attribute.Key("allgood.check.message")
+	AttributeCheckStatus  = attribute.Key("allgood.check.status")
+)
+
+// telemetry holds the OpenTelemetry instruments of an Engine, they are
+// no-ops until providers are set.
+type telemetry struct {
+	tracer       trace.Tracer
+	duration     metric.Float64Histogram
+	registration metric.Registration
+}
+
+func newTelemetry() telemetry {
+	duration, _ := metricnoop.Meter{}.Float64Histogram("")
+	return telemetry{
+		tracer:   tracenoop.NewTracerProvider().Tracer(instrumentationName),
+		duration: duration,
+	}
+}
+
+// SetTracerProvider sets the provider of the tracer every check run is
+// traced with. the span of a run is a child of the span in the context
+// the checks are run with, e.g. the one of the http request. a nil
+// provider disables tracing.
+func (e *Engine) SetTracerProvider(tp trace.TracerProvider) {
+	if tp == nil {
+		tp = tracenoop.NewTracerProvider()
+	}
+	tracer := tp.Tracer(instrumentationName, trace.WithInstrumentationVersion(Version))
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.telemetry.tracer = tracer
+}
+
+// SetMeterProvider sets the provider of the meter recording the
+// allgood.check.duration histogram of every check run and the
+// allgood.check.status gauge of the cached results, which is 1 for the
+// current status of a check and 0 for the others. a nil provider
+// disables metrics.
+func (e *Engine) SetMeterProvider(mp metric.MeterProvider) error {
+	if mp == nil {
+		mp = metricnoop.NewMeterProvider()
+	}
+	meter := mp.Meter(instrumentationName, metric.WithInstrumentationVersion(Version))
+	duration, err := meter.Float64Histogram("allgood.check.duration",
+		metric.WithDescription("Duration of the check runs."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10),
+	)
+	if err != nil {
+		return fmt.Errorf("creating duration histogram: %w", err)
+	}
+	status, err := meter.Int64ObservableGauge("allgood.check.status",
+		metric.WithDescription("Status of the check, 1 for the current status."),
+	)
+	if err != nil {
+		return fmt.Errorf("creating status gauge: %w", err)
+	}
+	registration, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		e.observeStatus(o, status)
+		return nil
+	}, status)
+	if err != nil {
+		return fmt.Errorf("registering status callback: %w", err)
+	}
+
+	e.mu.Lock()
+	previous := e.telemetry.registration
+	e.telemetry.duration = duration
+	e.telemetry.registration = registration
+	e.mu.Unlock()
+	if previous != nil {
+		return previous.Unregister()
+	}
+	return nil
+}
+
+// observeStatus observes the status gauge of every cached result.
+func (e *Engine) observeStatus(o metric.Observer, gauge metric.Int64ObservableGauge) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, id := range e.order {
+		result, exists := e.results[id]
+		if !exists || !e.checks[id].Enabled {
+			continue
+		}
+		for _, s := range checkStatuses {
+			var value int64
+			if s == result.Status {
+				value = 1
+			}
+			o.ObserveInt64(gauge, value, metric.WithAttributes(append(checkAttributes(e.checks[id]), AttributeCheckStatus.String(string(s)))...))
+		}
+	}
+}
+
+// startSpan starts the span of a check run.
+func (e *Engine) startSpan(ctx context.Context, check CheckConfig) (context.Context, trace.Span) {
+	e.mu.Lock()
+	tracer := e.telemetry.tracer
+	e.mu.Unlock()
+	return tracer.Start(ctx, "allgood.check "+check.Name, trace.WithAttributes(checkAttributes(check)...))
+}
+
+// recordRun ends the span of a check run and records its duration.
+func (e *Engine) recordRun(ctx context.Context, span trace.Span, check CheckConfig, result models.Result) {
+	span.SetAttributes(
+		AttributeCheckSuccess.Bool(result.Success),
+		AttributeCheckMessage.String(result.Message),
+		AttributeCheckStatus.String(string(result.Status)),
+	)
+	if !result.Success {
+		span.SetStatus(codes.Error, result.Message)
+	}
+	span.End()
+
+	e.mu.Lock()
+	duration := e.telemetry.duration
+	e.mu.Unlock()
+	duration.Record(ctx, result.Duration.Seconds(), metric.WithAttributes(append(checkAttributes(check),
+		AttributeCheckSuccess.Bool(result.Success),
+		AttributeCheckStatus.String(string(result.Status)),
+	)...))
+}
+
+// checkAttributes returns the attributes identifying a check.
+func checkAttributes(check CheckConfig) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		AttributeCheckID.String(check.Id.String()),
+		AttributeCheckKey.String(check.Key),
+		AttributeCheckName.String(check.Name),
+		AttributeCheckType.String(string(check.Type)),
+	}
+}
@@ -0,0 +1,129 @@
+package allgood
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestCheckRunsAreTraced(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	e := NewEngine(
+		testCheck("up", passing),
+		testCheck("down", func(context.Context) (bool, string) {
+			return false, "connection refused"
+		}),
+	)
+	e.SetTracerProvider(provider)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "GET /healthcheck")
+	e.collectResults(ctx, true, nil)
+	parent.End()
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	tests := []struct {
+		span    string
+		key     string
+		success bool
+		message string
+		status  codes.Code
+	}{
+		{span: "allgood.check up", key: "test/up", success: true, message: "ok", status: codes.Unset},
+		{span: "allgood.check down", key: "test/down", success: false, message: "connection refused", status: codes.Error},
+	}
+	for _, tt := range tests {
+		span, exists := spans[tt.span]
+		if !exists {
+			t.Fatalf("no span %q in %v", tt.span, spans)
+		}
+		if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("%s isn't a child of the request span", tt.span)
+		}
+		if span.Status.Code != tt.status {
+			t.Errorf("%s status = %v, want %v", tt.span, span.Status.Code, tt.status)
+		}
+		attributes := attribute.NewSet(span.Attributes...)
+		want := []attribute.KeyValue{
+			AttributeCheckID.String(CheckID(tt.key).String()),
+			AttributeCheckKey.String(tt.key),
+			AttributeCheckType.String("test"),
+			AttributeCheckSuccess.Bool(tt.success),
+			AttributeCheckMessage.String(tt.message),
+		}
+		for _, kv := range want {
+			if value, exists := attributes.Value(kv.Key); !exists || value != kv.Value {
+				t.Errorf("%s attribute %s = %v, want %v", tt.span, kv.Key, value.Emit(), kv.Value.Emit())
+			}
+		}
+	}
+}
+
+func TestCheckRunsAreMeasured(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	e := NewEngine(
+		testCheck("up", passing),
+		testCheck("down", func(context.Context) (bool, string) {
+			return false, "connection refused"
+		}),
+	)
+	if err := e.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))); err != nil {
+		t.Fatal(err)
+	}
+	e.collectResults(context.Background(), true, nil)
+	e.collectResults(context.Background(), true, nil)
+
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatal(err)
+	}
+	metrics := map[string]metricdata.Metrics{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			metrics[m.Name] = m
+		}
+	}
+
+	histogram, ok := metrics["allgood.check.duration"].Data.(metricdata.Histogram[float64])
+	if !ok {
+		t.Fatalf("allgood.check.duration = %T, want a float64 histogram", metrics["allgood.check.duration"].Data)
+	}
+	counts := map[string]uint64{}
+	for _, point := range histogram.DataPoints {
+		key, _ := point.Attributes.Value(AttributeCheckKey)
+		success, _ := point.Attributes.Value(AttributeCheckSuccess)
+		if success.AsBool() != (key.AsString() == "test/up") {
+			t.Errorf("%s recorded with success %t", key.AsString(), success.AsBool())
+		}
+		counts[key.AsString()] += point.Count
+	}
+	if counts["test/up"] != 2 || counts["test/down"] != 2 {
+		t.Errorf("recorded runs = %v, want 2 of each check", counts)
+	}
+
+	gauge, ok := metrics["allgood.check.status"].Data.(metricdata.Gauge[int64])
+	if !ok {
+		t.Fatalf("allgood.check.status = %T, want an int64 gauge", metrics["allgood.check.status"].Data)
+	}
+	current := map[string]string{}
+	for _, point := range gauge.DataPoints {
+		if point.Value != 1 {
+			continue
+		}
+		key, _ := point.Attributes.Value(AttributeCheckKey)
+		status, _ := point.Attributes.Value(AttributeCheckStatus)
+		current[key.AsString()] = status.AsString()
+	}
+	if len(gauge.DataPoints) != 2*len(checkStatuses) || current["test/up"] != "pass" || current["test/down"] != "fail" {
+		t.Errorf("status gauge has %d points with current statuses %v", len(gauge.DataPoints), current)
+	}
+}
@@ -0,0 +1,127 @@
+package allgood
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saintmalik/allgood/internal/models"
+)
+
+// Probe is a kind of probe handler a check is evaluated by. the probe
+// handlers accept the same query filters as HealthCheckHandler.
+type Probe string
+
+const (
+	// ProbeLiveness checks tell if the app should be restarted.
+	ProbeLiveness Probe = "liveness"
+	// ProbeReadiness checks tell if the app can take traffic.
+	ProbeReadiness Probe = "readiness"
+	// ProbeStartup checks tell if the app has finished starting.
+	ProbeStartup Probe = "startup"
+)
+
+// startupState tracks which startup checks have passed at least once.
+type startupState struct {
+	complete bool
+	passes   map[uuid.UUID]struct{}
+}
+
+// passed records a successful run of a startup check.
+func (s *startupState) passed(id uuid.UUID) {
+	if s.passes == nil {
+		s.passes = make(map[uuid.UUID]struct{})
+	}
+	s.passes[id] = struct{}{}
+}
+
+// hasProbe reports whether the check is evaluated by probe.
+func (c CheckConfig) hasProbe(probe Probe) bool {
+	if len(c.Probes) == 0 {
+		return probe == ProbeReadiness
+	}
+	for _, p := range c.Probes {
+		if p == probe {
+			return true
+		}
+	}
+	return false
+}
+
+// LivenessHandler provides the http.HandlerFunc for a liveness probe.
+// it only evaluates checks assigned to ProbeLiveness and responds with
+// 200 when there are none.
+func (e *Engine) LivenessHandler() http.HandlerFunc {
+	return e.probeHandler(ProbeLiveness)
+}
+
+// ReadinessHandler provides the http.HandlerFunc for a readiness probe.
+// it only evaluates checks assigned to ProbeReadiness, which checks
+// without any probe are.
+func (e *Engine) ReadinessHandler() http.HandlerFunc {
+	return e.probeHandler(ProbeReadiness)
+}
+
+// StartupHandler provides the http.HandlerFunc for a startup probe. it
+// only evaluates checks assigned to ProbeStartup and keeps failing
+// until every one of them has passed once, after that it responds
+// with 200 without running them again.
+func (e *Engine) StartupHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f, ok := negotiateFormat(w, r)
+		if !ok {
+			return
+		}
+		if e.startupComplete() {
+			e.writeResults(w, r, f, []models.Result{}, time.Now(), StatusOK, http.StatusOK)
+			return
+		}
+
+		fresh, _ := strconv.ParseBool(r.URL.Query().Get("refresh"))
+		results, checkedAt := e.collectResults(r.Context(), fresh, and(queryFilter(r), func(check CheckConfig) bool {
+			return check.hasProbe(ProbeStartup)
+		}))
+		status, statusCode := overallStatus(results)
+		if !e.startupComplete() {
+			status, statusCode = StatusError, http.StatusServiceUnavailable
+		}
+		e.writeResults(w, r, f, results, checkedAt, status, statusCode)
+	}
+}
+
+// probeHandler serves the checks assigned to probe.
+func (e *Engine) probeHandler(probe Probe) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f, ok := negotiateFormat(w, r)
+		if !ok {
+			return
+		}
+		fresh, _ := strconv.ParseBool(r.URL.Query().Get("refresh"))
+		results, checkedAt := e.collectResults(r.Context(), fresh, and(queryFilter(r), func(check CheckConfig) bool {
+			return check.hasProbe(probe)
+		}))
+		status, statusCode := overallStatus(results)
+		e.writeResults(w, r, f, results, checkedAt, status, statusCode)
+	}
+}
+
+// startupComplete reports whether every enabled startup check has
+// passed at least once. once it has, it stays complete.
+func (e *Engine) startupComplete() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.startup.complete {
+		return true
+	}
+	for id, check := range e.checks {
+		if !check.Enabled || !check.hasProbe(ProbeStartup) {
+			continue
+		}
+		if _, exists := e.startup.passes[id]; !exists {
+			return false
+		}
+	}
+	e.startup.complete = true
+	return true
+}
@@ -0,0 +1,43 @@
+package allgood
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStartupHandlerWaitsForARealPass(t *testing.T) {
+	var up atomic.Bool
+	e := NewEngine(func() CheckConfig {
+		config := testCheck("migrations", func(context.Context) (bool, string) {
+			if up.Load() {
+				return true, "ok"
+			}
+			return false, "down"
+		})()
+		config.Probes = []Probe{ProbeStartup}
+		config.FailureThreshold = 3
+		return config
+	})
+	probe := func() int {
+		rec := httptest.NewRecorder()
+		e.StartupHandler()(rec, httptest.NewRequest(http.MethodGet, "/?refresh=true&format=json", nil))
+		return rec.Code
+	}
+
+	for i := 0; i < 2; i++ {
+		if code := probe(); code != http.StatusServiceUnavailable {
+			t.Fatalf("probe %d below the failure threshold = %d, want 503 until the check passed", i+1, code)
+		}
+	}
+	up.Store(true)
+	if code := probe(); code != http.StatusOK {
+		t.Fatalf("probe after the check passed = %d, want 200", code)
+	}
+	up.Store(false)
+	if code := probe(); code != http.StatusOK {
+		t.Fatalf("probe after startup completed = %d, want 200", code)
+	}
+}
@@ -0,0 +1,90 @@
+package allgood
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// maxStackFrames is the number of frames kept in the stack trace of a
+// panicking check.
+const maxStackFrames = 16
+
+// PanicHandler is called when the HandlerFunc of a check panics, with
+// the recovered value and the stack trace of the panic. use it to
+// report panics to an error tracker.
+type PanicHandler func(check CheckConfig, recovered any, stack []byte)
+
+// SetPanicHandler sets the function called when a check panics. the
+// panic itself is always recovered and reported as a failed check.
+func (e *Engine) SetPanicHandler(handler PanicHandler) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.panicHandler = handler
+}
+
+// callCheck calls the StatusFunc or HandlerFunc of check, turning a
+// panic into a failed check carrying the panic value and a trimmed stack
+// trace. a check reporting anything but CheckPass or CheckWarn fails.
+func (e *Engine) callCheck(ctx context.Context, check CheckConfig) (status CheckStatus, message string, stack string) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		trace := debug.Stack()
+		status = CheckFail
+		message = fmt.Sprintf("Check panicked: %v", recovered)
+		stack = trimStack(string(trace))
+
+		e.mu.Lock()
+		handler := e.panicHandler
+		e.mu.Unlock()
+		if handler != nil {
+			handler(check, recovered, trace)
+		}
+	}()
+	// the handlers are called right here so the stack of a panic ends
+	// at callCheck, see trimStack.
+	if check.StatusFunc != nil {
+		status, message = check.StatusFunc(ctx)
+	} else {
+		var success bool
+		success, message = check.HandlerFunc(ctx)
+		status = CheckPass
+		if !success {
+			status = CheckFail
+		}
+	}
+	if status != CheckPass && status != CheckWarn {
+		status = CheckFail
+	}
+	return status, message, ""
+}
+
+// trimStack drops the frames of the panic machinery and of the Engine
+// from a stack trace produced by debug.Stack so only the frames of the
+// check are left, and keeps at most maxStackFrames of them.
+func trimStack(stack string) string {
+	lines := strings.Split(strings.TrimSpace(stack), "\n")
+	// every frame is a function line followed by a file line, the
+	// frames of the check come right after the call to panic and end
+	// at callCheck.
+	for i, line := range lines {
+		if strings.HasPrefix(line, "panic(") {
+			lines = lines[i+2:]
+			break
+		}
+	}
+	for i, line := range lines {
+		if strings.Contains(line, "allgood.(*Engine).callCheck(") {
+			lines = lines[:i]
+			break
+		}
+	}
+	if len(lines) > maxStackFrames*2 {
+		lines = lines[:maxStackFrames*2]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,69 @@
+package allgood
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// explode panics after recursing depth times, so the stack of its panic
+// has depth+1 frames of its own.
+func explode(depth int) (bool, string) {
+	if depth == 0 {
+		panic("boom")
+	}
+	return explode(depth - 1)
+}
+
+func TestPanickingCheckFails(t *testing.T) {
+	tests := []struct {
+		name   string
+		depth  int
+		frames int
+	}{
+		// the frames of explode and of the function calling it.
+		{name: "shallow", depth: 2, frames: 4},
+		{name: "deep", depth: 3 * maxStackFrames, frames: maxStackFrames},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine(testCheck("explosive", func(context.Context) (bool, string) {
+				return explode(tt.depth)
+			}))
+			var mu sync.Mutex
+			var recovered []any
+			e.SetPanicHandler(func(check CheckConfig, r any, stack []byte) {
+				mu.Lock()
+				defer mu.Unlock()
+				if check.Name != "explosive" || len(stack) == 0 {
+					t.Errorf("PanicHandler called for %q with %d bytes of stack", check.Name, len(stack))
+				}
+				recovered = append(recovered, r)
+			})
+
+			results, _ := e.collectResults(context.Background(), true, nil)
+			result := results[0]
+			if result.Success || !strings.Contains(result.Message, "boom") {
+				t.Errorf("result = %t %q, want a failure mentioning the panic", result.Success, result.Message)
+			}
+
+			lines := strings.Split(result.Stack, "\n")
+			if !strings.Contains(lines[0], "allgood.explode(") {
+				t.Errorf("stack starts at %q, want the frame of the check", lines[0])
+			}
+			if frames := len(lines) / 2; frames != tt.frames {
+				t.Errorf("stack has %d frames, want %d:\n%s", frames, tt.frames, result.Stack)
+			}
+			if strings.Contains(result.Stack, "callCheck") {
+				t.Errorf("stack includes the Engine:\n%s", result.Stack)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(recovered) != 1 || recovered[0] != "boom" {
+				t.Errorf("PanicHandler got %v, want boom once", recovered)
+			}
+		})
+	}
+}
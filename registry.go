@@ -0,0 +1,237 @@
+package allgood
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// checkNamespace is the namespace of the name based UUIDs derived from
+// check keys.
+var checkNamespace = uuid.MustParse("43fa211d-24ed-4028-8c05-ed73fd86514a")
+
+var (
+	// ErrDuplicateCheck is returned when registering a check that is a
+	// duplicate under DuplicateReject.
+	ErrDuplicateCheck = errors.New("duplicate check")
+	// ErrInvalidCheck is returned when registering a check that can't
+	// be run.
+	ErrInvalidCheck = errors.New("invalid check")
+)
+
+// CheckID returns the Id the Engine derives from a check key.
+func CheckID(key string) uuid.UUID {
+	return uuid.NewSHA1(checkNamespace, []byte(key))
+}
+
+// DuplicatePolicy decides what happens when a check being registered
+// duplicates one already in the Engine. a check is a duplicate when it
+// has the Id or Key of a registered check, or when its type is listed in
+// AvoidDuplicateFor and a check of that type is registered.
+//
+// checks without a Key never duplicate each other by Id, the second
+// check of a type and name gets the default key suffixed with "#2", the
+// third "#3" and so on.
+type DuplicatePolicy int
+
+const (
+	// DuplicateKeepFirst skips the new check. it is the default, a
+	// check with the Id of a registered one is reported with
+	// ErrDuplicateCheck while a check of a type in AvoidDuplicateFor is
+	// skipped silently.
+	DuplicateKeepFirst DuplicatePolicy = iota
+	// DuplicateReplace removes the registered check in favor of the
+	// new one.
+	DuplicateReplace
+	// DuplicateReject skips the new check and reports ErrDuplicateCheck.
+	DuplicateReject
+)
+
+// SetDuplicatePolicy sets how checks added with AddChecks that
+// duplicate registered checks are handled.
+func (e *Engine) SetDuplicatePolicy(policy DuplicatePolicy) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.duplicatePolicy = policy
+}
+
+// RemoveCheck removes a check from the Engine. it reports whether the
+// check was registered.
+func (e *Engine) RemoveCheck(id uuid.UUID) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.remove(id)
+}
+
+// GetCheck returns the check registered with id.
+func (e *Engine) GetCheck(id uuid.UUID) (CheckConfig, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	check, exists := e.checks[id]
+	return check, exists
+}
+
+// GetCheckByKey returns the check registered with key.
+func (e *Engine) GetCheckByKey(key string) (CheckConfig, bool) {
+	checks := e.findChecks(func(check CheckConfig) bool { return check.Key == key })
+	if len(checks) == 0 {
+		return CheckConfig{}, false
+	}
+	return checks[0], true
+}
+
+// ListChecks returns every registered check in the order they were
+// registered.
+func (e *Engine) ListChecks() []CheckConfig {
+	return e.findChecks(func(CheckConfig) bool { return true })
+}
+
+// ChecksByName returns the registered checks named name.
+func (e *Engine) ChecksByName(name string) []CheckConfig {
+	return e.findChecks(func(check CheckConfig) bool { return check.Name == name })
+}
+
+// ChecksByType returns the registered checks of type checkType.
+func (e *Engine) ChecksByType(checkType CheckType) []CheckConfig {
+	return e.findChecks(func(check CheckConfig) bool { return check.Type == checkType })
+}
+
+// findChecks returns the registered checks matching filter in the
+// order they were registered.
+func (e *Engine) findChecks(filter checkFilter) []CheckConfig {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var checks []CheckConfig
+	for _, id := range e.order {
+		if check := e.checks[id]; filter(check) {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// register adds config to the Engine following its DuplicatePolicy. it
+// must be called with e.mu held.
+func (e *Engine) register(config CheckConfig) error {
+	if config.HandlerFunc == nil && config.StatusFunc == nil {
+		return fmt.Errorf("%w: %q has no HandlerFunc or StatusFunc", ErrInvalidCheck, config.Name)
+	}
+	if config.Key == "" {
+		config.Key = e.defaultKey(config)
+	}
+	if config.Id == uuid.Nil {
+		config.Id = CheckID(config.Key)
+	}
+
+	duplicates := e.duplicatesOf(config)
+	if len(duplicates) > 0 {
+		switch e.duplicatePolicy {
+		case DuplicateReject:
+			return fmt.Errorf("%w: %q duplicates %q", ErrDuplicateCheck, config.Name, e.checks[duplicates[0]].Name)
+		case DuplicateReplace:
+			for _, id := range duplicates {
+				if id != config.Id {
+					e.remove(id)
+				}
+			}
+		default:
+			if avoidsDuplicates(config.Type) {
+				return nil
+			}
+			return fmt.Errorf("%w: %q has the id or key of a registered check", ErrDuplicateCheck, config.Key)
+		}
+	}
+
+	if _, exists := e.checks[config.Id]; !exists {
+		e.order = append(e.order, config.Id)
+	} else {
+		delete(e.results, config.Id)
+	}
+	e.checks[config.Id] = config
+	if e.scheduler != nil {
+		e.scheduler.schedule(e, config.Id)
+	}
+	return nil
+}
+
+// defaultKey returns the Type and Name of config joined by a slash,
+// suffixed with "#2", "#3" and so on when registered checks already have
+// that key. the types in AvoidDuplicateFor are never suffixed, there is
+// only one check of each. it must be called with e.mu held.
+func (e *Engine) defaultKey(config CheckConfig) string {
+	base := string(config.Type) + "/" + config.Name
+	if avoidsDuplicates(config.Type) {
+		return base
+	}
+	key := base
+	for n := 2; e.keyTaken(key); n++ {
+		key = base + "#" + strconv.Itoa(n)
+	}
+	return key
+}
+
+// keyTaken reports whether a registered check has key, or the Id derived
+// from it. it must be called with e.mu held.
+func (e *Engine) keyTaken(key string) bool {
+	for _, id := range e.order {
+		if sameCheck(e.checks[id], CheckConfig{Id: CheckID(key), Key: key}) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameCheck reports whether a and b have the same Id or Key.
+func sameCheck(a, b CheckConfig) bool {
+	return a.Id == b.Id || a.Key == b.Key
+}
+
+// duplicatesOf returns the ids of the registered checks config
+// duplicates. it must be called with e.mu held.
+func (e *Engine) duplicatesOf(config CheckConfig) []uuid.UUID {
+	var duplicates []uuid.UUID
+	avoid := avoidsDuplicates(config.Type)
+	for _, id := range e.order {
+		if sameCheck(e.checks[id], config) || (avoid && e.checks[id].Type == config.Type) {
+			duplicates = append(duplicates, id)
+		}
+	}
+	return duplicates
+}
+
+// avoidsDuplicates reports whether checkType is in AvoidDuplicateFor.
+func avoidsDuplicates(checkType CheckType) bool {
+	for _, t := range AvoidDuplicateFor {
+		if t == checkType {
+			return true
+		}
+	}
+	return false
+}
+
+// isRegistered reports whether a check is registered. it must be
+// called with e.mu held.
+func (e *Engine) isRegistered(id uuid.UUID) bool {
+	_, exists := e.checks[id]
+	return exists
+}
+
+// remove removes a check and its cached result. it must be called with
+// e.mu held.
+func (e *Engine) remove(id uuid.UUID) bool {
+	if _, exists := e.checks[id]; !exists {
+		return false
+	}
+	delete(e.checks, id)
+	delete(e.results, id)
+	delete(e.startup.passes, id)
+	for i, registered := range e.order {
+		if registered == id {
+			e.order = append(e.order[:i], e.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package allgood
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func passing(context.Context) (bool, string) {
+	return true, "ok"
+}
+
+func TestChecksWithDefaultKeysAllRun(t *testing.T) {
+	mk := func() CheckInit {
+		return testCheck("Postgres Connection", passing)
+	}
+
+	e := NewEngine(mk(), mk())
+	if err := e.AddChecks(mk()); err != nil {
+		t.Fatalf("AddChecks() = %v, want nil", err)
+	}
+
+	checks := e.ListChecks()
+	want := []string{"test/Postgres Connection", "test/Postgres Connection#2", "test/Postgres Connection#3"}
+	if len(checks) != len(want) {
+		t.Fatalf("registered %d checks, want %d", len(checks), len(want))
+	}
+	for i, check := range checks {
+		if check.Key != want[i] || check.Id != CheckID(want[i]) {
+			t.Errorf("check %d has key %q and id %s, want %q and %s", i, check.Key, check.Id, want[i], CheckID(want[i]))
+		}
+	}
+}
+
+func TestDuplicateKeysAreReported(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy DuplicatePolicy
+		want   error
+		checks int
+	}{
+		{name: "keep first", policy: DuplicateKeepFirst, want: ErrDuplicateCheck, checks: 1},
+		{name: "reject", policy: DuplicateReject, want: ErrDuplicateCheck, checks: 1},
+		{name: "replace", policy: DuplicateReplace, want: nil, checks: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine()
+			e.SetDuplicatePolicy(tt.policy)
+			keyed := func() CheckInit {
+				return func() CheckConfig {
+					config := testCheck("primary", passing)()
+					WithCheckKey("db/primary")()(&config)
+					return config
+				}
+			}
+			if err := e.AddChecks(keyed()); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.AddChecks(keyed()); !errors.Is(err, tt.want) {
+				t.Fatalf("AddChecks() = %v, want %v", err, tt.want)
+			}
+			if n := len(e.ListChecks()); n != tt.checks {
+				t.Fatalf("registered %d checks, want %d", n, tt.checks)
+			}
+		})
+	}
+}
+
+func TestAvoidDuplicateForKeepsFirstSilently(t *testing.T) {
+	e := NewEngine(WithCheckMemoryUsage(90))
+	if err := e.AddChecks(WithCheckMemoryUsage(80, WithCheckName("Other Memory Usage"))); err != nil {
+		t.Fatalf("AddChecks() = %v, want nil", err)
+	}
+	if n := len(e.ChecksByType(CheckTypeMemoryUsage)); n != 1 {
+		t.Fatalf("registered %d memory checks, want 1", n)
+	}
+
+	e.SetDuplicatePolicy(DuplicateReject)
+	if err := e.AddChecks(WithCheckMemoryUsage(80)); !errors.Is(err, ErrDuplicateCheck) {
+		t.Fatalf("AddChecks() = %v, want %v", err, ErrDuplicateCheck)
+	}
+}
+
+func TestNewEngineEReturnsRegistrationErrors(t *testing.T) {
+	invalid := func() CheckConfig {
+		return CheckConfig{Type: "test", Name: "no handler", Enabled: true}
+	}
+	e, err := NewEngineE(testCheck("valid", passing), invalid, testCheck("valid", passing))
+	if !errors.Is(err, ErrInvalidCheck) {
+		t.Fatalf("NewEngineE() = %v, want %v", err, ErrInvalidCheck)
+	}
+	if n := len(e.ListChecks()); n != 2 {
+		t.Fatalf("registered %d checks, want the 2 valid ones", n)
+	}
+
+	keyed := func() CheckConfig {
+		config := testCheck("primary", passing)()
+		config.Key = "db/primary"
+		return config
+	}
+	if _, err := NewEngineE(keyed, keyed); !errors.Is(err, ErrDuplicateCheck) {
+		t.Fatalf("NewEngineE() = %v, want %v", err, ErrDuplicateCheck)
+	}
+}
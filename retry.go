@@ -0,0 +1,93 @@
+package allgood
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/saintmalik/allgood/internal/models"
+)
+
+// RetryPolicy is how a failing check is retried within a single run.
+// the zero value runs the check once.
+type RetryPolicy struct {
+	// Attempts is the total number of times the check is tried.
+	Attempts int
+	// Backoff is the delay before the first retry, it doubles after
+	// every retry.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between retries when it is set.
+	MaxBackoff time.Duration
+	// AttemptTimeout is the deadline of every attempt when it is set,
+	// the check Timeout still bounds all attempts together.
+	AttemptTimeout time.Duration
+}
+
+// callWithRetry calls the check following its RetryPolicy until it
+// passes or warns, the attempts run out or ctx is done. it returns the
+// outcome of the last attempt and the number of attempts made.
+func (e *Engine) callWithRetry(ctx context.Context, check CheckConfig) (status CheckStatus, message string, stack string, attempts int) {
+	policy := check.Retry
+	backoff := policy.Backoff
+	for {
+		attempts++
+		status, message, stack = e.callAttempt(ctx, check, policy.AttemptTimeout)
+		if status != CheckFail || attempts >= policy.Attempts || ctx.Err() != nil {
+			return status, message, stack, attempts
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return status, message, stack, attempts
+		case <-timer.C:
+		}
+		backoff *= 2
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
+
+// callAttempt makes a single attempt of check within timeout.
+func (e *Engine) callAttempt(ctx context.Context, check CheckConfig, timeout time.Duration) (CheckStatus, string, string) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return e.callCheck(ctx, check)
+}
+
+// applyThresholds keeps reporting the previous outcome of a check until
+// it has failed or succeeded enough times in a row to flip.
+func applyThresholds(check CheckConfig, wasHealthy bool, result models.Result) models.Result {
+	healthy := wasHealthy
+	switch {
+	case wasHealthy && result.ConsecutiveFailures >= threshold(check.FailureThreshold):
+		healthy = false
+	case !wasHealthy && result.ConsecutiveSuccesses >= threshold(check.SuccessThreshold):
+		healthy = true
+	}
+	if healthy == result.Success {
+		return result
+	}
+
+	if healthy {
+		result.Message += fmt.Sprintf(" (failure %d of %d before failing)", result.ConsecutiveFailures, threshold(check.FailureThreshold))
+	} else {
+		result.Message += fmt.Sprintf(" (success %d of %d before healthy)", result.ConsecutiveSuccesses, threshold(check.SuccessThreshold))
+	}
+	result.Success = healthy
+	result.Status = checkStatus(check, healthy)
+	return result
+}
+
+// threshold returns n, defaulting to 1.
+func threshold(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
+}
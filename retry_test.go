@@ -0,0 +1,139 @@
+package allgood
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/saintmalik/allgood/internal/models"
+)
+
+// scriptedCheck returns a check reporting outcomes in turn, it keeps
+// reporting the last one once they run out.
+func scriptedCheck(name string, outcomes ...bool) (CheckInit, *atomic.Int32) {
+	var calls atomic.Int32
+	return testCheck(name, func(context.Context) (bool, string) {
+		i := int(calls.Add(1)) - 1
+		if i >= len(outcomes) {
+			i = len(outcomes) - 1
+		}
+		if outcomes[i] {
+			return true, "ok"
+		}
+		return false, "down"
+	}), &calls
+}
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes []bool
+		policy   RetryPolicy
+		success  bool
+		attempts int
+	}{
+		{name: "no retries", outcomes: []bool{false, true}, success: false, attempts: 1},
+		{name: "succeeds on retry", outcomes: []bool{false, false, true}, policy: RetryPolicy{Attempts: 3}, success: true, attempts: 3},
+		{name: "runs out of attempts", outcomes: []bool{false}, policy: RetryPolicy{Attempts: 2}, success: false, attempts: 2},
+		{name: "stops on success", outcomes: []bool{true, false}, policy: RetryPolicy{Attempts: 3}, success: true, attempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			init, calls := scriptedCheck("flaky", tt.outcomes...)
+			check := init()
+			check.Retry = tt.policy
+			check.Retry.Backoff = time.Millisecond
+
+			status, _, _, attempts := NewEngine().callWithRetry(context.Background(), check)
+			if success := status == CheckPass; success != tt.success || attempts != tt.attempts || int(calls.Load()) != tt.attempts {
+				t.Fatalf("%s after %d attempts and %d calls, want success %t after %d", status, attempts, calls.Load(), tt.success, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestRetryStopsAtCheckTimeout(t *testing.T) {
+	init, calls := scriptedCheck("down", false)
+	check := init()
+	check.Retry = RetryPolicy{Attempts: 100, Backoff: 20 * time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	status, _, _, attempts := NewEngine().callWithRetry(ctx, check)
+	if status != CheckFail || attempts >= 100 || int(calls.Load()) != attempts {
+		t.Fatalf("%s after %d attempts, want a failure cut short by the deadline", status, attempts)
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes []bool
+		failures int
+		success  int
+		want     []models.Status
+	}{
+		{
+			name:     "default thresholds flip right away",
+			outcomes: []bool{false, true},
+			want:     []models.Status{models.StatusFail, models.StatusPass},
+		},
+		{
+			name:     "first run below the failure threshold",
+			outcomes: []bool{false, false, false, true},
+			failures: 3,
+			want:     []models.Status{models.StatusPass, models.StatusPass, models.StatusFail, models.StatusPass},
+		},
+		{
+			name:     "failures interrupted by a success",
+			outcomes: []bool{true, false, false, true, false, false},
+			failures: 3,
+			want:     []models.Status{models.StatusPass, models.StatusPass, models.StatusPass, models.StatusPass, models.StatusPass, models.StatusPass},
+		},
+		{
+			name:     "success threshold",
+			outcomes: []bool{false, true, false, true, true},
+			success:  2,
+			want:     []models.Status{models.StatusFail, models.StatusFail, models.StatusFail, models.StatusFail, models.StatusPass},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			init, _ := scriptedCheck("flaky", tt.outcomes...)
+			e := NewEngine(func() CheckConfig {
+				check := init()
+				check.FailureThreshold = tt.failures
+				check.SuccessThreshold = tt.success
+				return check
+			})
+			for i, want := range tt.want {
+				results, _ := e.collectResults(context.Background(), true, nil)
+				if got := results[0].Status; got != want {
+					t.Fatalf("run %d: status %s (%s), want %s", i+1, got, results[0].Message, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFailureThresholdKeepsFirstRunHealthy(t *testing.T) {
+	init, _ := scriptedCheck("redis", false)
+	e := NewEngine(func() CheckConfig {
+		check := init()
+		check.FailureThreshold = 3
+		return check
+	})
+
+	rec := httptest.NewRecorder()
+	e.HealthCheckHandler()(rec, httptest.NewRequest(http.MethodGet, "/?format=json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status code = %d, want 200 below the failure threshold", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "failure 1 of 3") {
+		t.Fatalf("body %s doesn't report the failure count", rec.Body)
+	}
+}
@@ -0,0 +1,174 @@
+package allgood
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saintmalik/allgood/internal/models"
+)
+
+// DefaultCheckInterval is how often a check runs once the Engine is
+// started when none is set with WithCheckInterval.
+const DefaultCheckInterval = 30 * time.Second
+
+// scheduler keeps track of the background check loops of a started
+// Engine.
+type scheduler struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running map[uuid.UUID]struct{}
+	wg      sync.WaitGroup
+}
+
+// Start runs every check in the background on its own interval and
+// caches the latest results. HealthCheckHandler serves those cached
+// results until the Engine is stopped.
+//
+// Start returns right away, the checks keep running until ctx is done
+// or Stop is called. calling Start on a started Engine does nothing.
+func (e *Engine) Start(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.scheduler != nil && e.scheduler.ctx.Err() == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	e.scheduler = &scheduler{
+		ctx:     ctx,
+		cancel:  cancel,
+		running: make(map[uuid.UUID]struct{}, len(e.checks)),
+	}
+	for _, id := range e.order {
+		e.scheduler.schedule(e, id)
+	}
+}
+
+// Stop stops the background checks started with Start and waits for
+// running checks to return.
+func (e *Engine) Stop() {
+	e.mu.Lock()
+	s := e.scheduler
+	e.scheduler = nil
+	e.mu.Unlock()
+	if s == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+}
+
+// schedule starts the background loop of a check unless it already has
+// one. it must be called with e.mu held.
+func (s *scheduler) schedule(e *Engine, id uuid.UUID) {
+	if _, exists := s.running[id]; exists {
+		return
+	}
+	s.running[id] = struct{}{}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			e.mu.Lock()
+			delete(s.running, id)
+			e.mu.Unlock()
+		}()
+		s.loop(e, id)
+	}()
+}
+
+// loop runs a check on its interval until the scheduler is stopped or
+// the check is removed from the Engine. the check config is looked up
+// on every run so EnableCheck and DisableCheck take effect.
+func (s *scheduler) loop(e *Engine, id uuid.UUID) {
+	e.mu.Lock()
+	check := e.checks[id]
+	e.mu.Unlock()
+
+	timer := time.NewTimer(jitter(check.Jitter))
+	defer timer.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		e.mu.Lock()
+		check, exists := e.checks[id]
+		e.mu.Unlock()
+		if !exists {
+			return
+		}
+		if check.Enabled {
+			e.execute(s.ctx, []CheckConfig{check})
+		}
+
+		interval := check.Interval
+		if interval <= 0 {
+			interval = DefaultCheckInterval
+		}
+		timer.Reset(interval + jitter(check.Jitter))
+	}
+}
+
+// collectResults returns the results of every enabled check matching
+// filter, a nil filter matches every check. while the Engine is started
+// the cached results are used and only checks that have none yet, or
+// that entered or left a maintenance window since, are run. fresh runs
+// every check regardless.
+//
+// checkedAt is when the oldest of the returned results was produced, or
+// now when checks were run or none matched.
+func (e *Engine) collectResults(ctx context.Context, fresh bool, filter checkFilter) (results []models.Result, checkedAt time.Time) {
+	e.mu.Lock()
+	now := time.Now()
+	started := e.scheduler != nil && e.scheduler.ctx.Err() == nil
+	results = []models.Result{}
+	var selected, missing []CheckConfig
+	var positions []int
+	for _, id := range e.order {
+		check := e.checks[id]
+		if !check.Enabled || (filter != nil && !filter(check)) {
+			continue
+		}
+		cached, exists := e.results[id]
+		_, _, inMaintenance := e.maintenanceOf(check, now)
+		if fresh || !started || !exists || inMaintenance != (cached.Status == models.StatusMaintenance) {
+			missing = append(missing, check)
+			positions = append(positions, len(results))
+		} else if checkedAt.IsZero() || cached.FinishedAt.Before(checkedAt) {
+			checkedAt = cached.FinishedAt
+		}
+		selected = append(selected, check)
+		results = append(results, cached)
+	}
+	e.mu.Unlock()
+
+	if len(missing) > 0 {
+		for i, result := range e.execute(ctx, missing) {
+			results[positions[i]] = result
+		}
+	}
+	if checkedAt.IsZero() {
+		checkedAt = time.Now()
+	}
+
+	e.mu.Lock()
+	for i, check := range selected {
+		results[i].SilencedUntil = e.silencedUntil(check, now)
+	}
+	e.mu.Unlock()
+	return results, checkedAt
+}
+
+// jitter returns a random duration in [0, max).
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
@@ -0,0 +1,234 @@
+package allgood
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/saintmalik/allgood/notify"
+)
+
+// recorder is a notifier keeping the notifications it is sent.
+type recorder struct {
+	mu            sync.Mutex
+	notifications []notify.Notification
+}
+
+func (r *recorder) Notify(n notify.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifications = append(r.notifications, n)
+	return nil
+}
+
+func (r *recorder) sent() []notify.Notification {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]notify.Notification{}, r.notifications...)
+}
+
+// testCheck returns the initializer of a check calling fn.
+func testCheck(name string, fn CheckContextFunc) CheckInit {
+	return func() CheckConfig {
+		return CheckConfig{Type: "test", Name: name, Enabled: true, HandlerFunc: fn}
+	}
+}
+
+// blockingCheck returns a check that signals started and fails once its
+// context is done.
+func blockingCheck(name string, started chan<- struct{}) CheckInit {
+	return testCheck(name, func(ctx context.Context) (bool, string) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return false, ctx.Err().Error()
+	})
+}
+
+func TestSchedulerRunsChecksInBackground(t *testing.T) {
+	var runs atomic.Int32
+	e := NewEngine(func() CheckConfig {
+		return CheckConfig{Type: "test", Name: "counter", Enabled: true, Interval: 10 * time.Millisecond, HandlerFunc: func(context.Context) (bool, string) {
+			runs.Add(1)
+			return true, "ok"
+		}}
+	})
+	e.Start(context.Background())
+	time.Sleep(100 * time.Millisecond)
+	e.Stop()
+
+	if n := runs.Load(); n < 3 {
+		t.Fatalf("check ran %d times in the background, want at least 3", n)
+	}
+	results, _ := e.collectResults(context.Background(), false, nil)
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("results = %+v, want the cached success", results)
+	}
+}
+
+func TestStopDoesNotCacheOrNotifyInterruptedChecks(t *testing.T) {
+	started := make(chan struct{}, 1)
+	e := NewEngine(blockingCheck("slow", started))
+	notifications := &recorder{}
+	e.SetNotifier(notifications)
+
+	e.Start(context.Background())
+	<-started
+	e.Stop()
+	if err := e.FlushNotifications(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if sent := notifications.sent(); len(sent) != 0 {
+		t.Fatalf("sent %d notifications for a cancelled check: %v", len(sent), sent)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if result, cached := e.results[CheckID("test/slow")]; cached {
+		t.Fatalf("cached the result of a cancelled check: %+v", result)
+	}
+}
+
+func TestDisconnectedRequestDoesNotCacheOrNotify(t *testing.T) {
+	started := make(chan struct{}, 1)
+	e := NewEngine(blockingCheck("slow", started))
+	notifications := &recorder{}
+	e.SetNotifier(notifications)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	results, _ := e.collectResults(ctx, false, nil)
+	if err := e.FlushNotifications(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].Success {
+		t.Fatalf("results = %+v, want the cancelled check reported", results)
+	}
+	if sent := notifications.sent(); len(sent) != 0 {
+		t.Fatalf("sent %d notifications for a cancelled check: %v", len(sent), sent)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.results) != 0 {
+		t.Fatalf("cached %d results of a cancelled request", len(e.results))
+	}
+}
+
+func TestTimedOutChecksAreCachedAndNotified(t *testing.T) {
+	started := make(chan struct{}, 1)
+	e := NewEngine(blockingCheck("slow", started))
+	e.SetTimeout(20 * time.Millisecond)
+	notifications := &recorder{}
+	e.SetNotifier(notifications)
+
+	results, _ := e.collectResults(context.Background(), false, nil)
+	if err := e.FlushNotifications(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].Success {
+		t.Fatalf("results = %+v, want the timed out check failing", results)
+	}
+	if sent := notifications.sent(); len(sent) != 1 || sent[0].Status != notify.StatusFail {
+		t.Fatalf("notifications = %v, want the check failing", sent)
+	}
+}
+
+func TestHealthCheckHandlerServesCachedResults(t *testing.T) {
+	var runs atomic.Int32
+	e := NewEngine(testCheck("cached", func(context.Context) (bool, string) {
+		runs.Add(1)
+		return true, "ok"
+	}))
+	e.Start(context.Background())
+	defer e.Stop()
+
+	// Start runs the check in the background, wait for its result.
+	deadline := time.Now().Add(time.Second)
+	for {
+		e.mu.Lock()
+		_, cached := e.results[CheckID("test/cached")]
+		e.mu.Unlock()
+		if cached {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the background run never finished")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		e.HealthCheckHandler()(rec, httptest.NewRequest(http.MethodGet, "/?format=json", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status code = %d, want 200", rec.Code)
+		}
+		if rec.Header().Get("Age") == "" {
+			t.Fatal("no Age header on a cached response")
+		}
+	}
+	if n := runs.Load(); n != 1 {
+		t.Fatalf("check ran %d times, want the cached result served", n)
+	}
+}
+
+func TestHealthCheckHandlerWithoutMatchingChecks(t *testing.T) {
+	e := NewEngine(testCheck("db", passing))
+	e.Start(context.Background())
+	defer e.Stop()
+
+	rec := httptest.NewRecorder()
+	e.HealthCheckHandler()(rec, httptest.NewRequest(http.MethodGet, "/?tag=none&format=json", nil))
+
+	if age := rec.Header().Get("Age"); age != "0" {
+		t.Fatalf("Age = %q, want 0", age)
+	}
+	var body struct {
+		CheckedAt time.Time `json:"checkedAt"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(body.CheckedAt) > time.Minute {
+		t.Fatalf("checkedAt = %s, want about now", body.CheckedAt)
+	}
+}
+
+func TestCheckTimeoutFreesTheWorker(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	e := NewEngine(
+		func() CheckConfig {
+			config := testCheck("stuck", AdaptCheckFunc(func() (bool, string) {
+				<-release
+				return true, "ok"
+			}))()
+			config.Timeout = 50 * time.Millisecond
+			return config
+		},
+		testCheck("instant", passing),
+	)
+	e.SetMaxConcurrency(1)
+	e.SetTimeout(time.Second)
+
+	begun := time.Now()
+	results, _ := e.collectResults(context.Background(), false, nil)
+	if elapsed := time.Since(begun); elapsed > 500*time.Millisecond {
+		t.Fatalf("checks took %s, want the stuck one given up after its timeout", elapsed)
+	}
+	if results[0].Success || results[0].Message != "Check timed out after 50ms" {
+		t.Errorf("stuck check = %t %q, want it timed out after 50ms", results[0].Success, results[0].Message)
+	}
+	if !results[1].Success {
+		t.Errorf("instant check = %t %q, want it passing", results[1].Success, results[1].Message)
+	}
+}
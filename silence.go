@@ -0,0 +1,160 @@
+package allgood
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSilence is returned when adding a silence that selects no
+// checks or has already ended.
+var ErrInvalidSilence = errors.New("invalid silence")
+
+// Silence stops the notifications of the checks it selects until it
+// ends. a check is selected when its Key is in Checks or it has any of
+// Tags. a check recovering is still notified about, so incidents opened
+// before the silence get resolved.
+type Silence struct {
+	ID     string    `json:"id"`
+	Checks []string  `json:"checks,omitempty"`
+	Tags   []string  `json:"tags,omitempty"`
+	Until  time.Time `json:"until"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// selects reports whether the silence applies to check.
+func (s Silence) selects(check CheckConfig) bool {
+	return contains(s.Checks, check.Key) || check.hasAnyTag(s.Tags)
+}
+
+// AddSilence silences the notifications of the checks selected by s
+// until s.Until. s is given a random ID unless it has one, a silence
+// with the same ID is replaced.
+func (e *Engine) AddSilence(s Silence) (Silence, error) {
+	if len(s.Checks) == 0 && len(s.Tags) == 0 {
+		return Silence{}, fmt.Errorf("%w: no checks or tags", ErrInvalidSilence)
+	}
+	if !s.Until.After(time.Now()) {
+		return Silence{}, fmt.Errorf("%w: until %s has passed", ErrInvalidSilence, s.Until.Format(time.RFC3339))
+	}
+	if s.ID == "" {
+		s.ID = uuid.NewString()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.removeSilence(s.ID)
+	e.silences = append(e.silences, s)
+	return s, nil
+}
+
+// RemoveSilence ends a silence early. it reports whether the silence
+// was active.
+func (e *Engine) RemoveSilence(id string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.pruneSilences(time.Now())
+	return e.removeSilence(id)
+}
+
+// Silences returns the active silences.
+func (e *Engine) Silences() []Silence {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.pruneSilences(time.Now())
+	return append([]Silence{}, e.silences...)
+}
+
+// SilencesHandler provides an admin http.HandlerFunc to manage
+// silences, it should only be reachable by operators.
+//
+//   - GET lists the active silences
+//   - POST adds the silence in the JSON body, which may give a
+//     "duration" such as "2h" instead of "until"
+//   - DELETE ?id= removes a silence
+func (e *Engine) SilencesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, e.Silences())
+		case http.MethodPost:
+			var body struct {
+				Silence
+				Duration string `json:"duration"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, fmt.Sprintf("decoding silence: %v", err), http.StatusBadRequest)
+				return
+			}
+			if body.Duration != "" {
+				duration, err := time.ParseDuration(body.Duration)
+				if err != nil {
+					http.Error(w, fmt.Sprintf("parsing duration: %v", err), http.StatusBadRequest)
+					return
+				}
+				body.Until = time.Now().Add(duration)
+			}
+			silence, err := e.AddSilence(body.Silence)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			writeJSON(w, http.StatusCreated, silence)
+		case http.MethodDelete:
+			if !e.RemoveSilence(r.URL.Query().Get("id")) {
+				http.Error(w, "silence not found", http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// silencedUntil returns when the last silence of check ends, zero when
+// it isn't silenced at now. it must be called with e.mu held.
+func (e *Engine) silencedUntil(check CheckConfig, now time.Time) time.Time {
+	var until time.Time
+	for _, s := range e.silences {
+		if s.Until.After(now) && s.Until.After(until) && s.selects(check) {
+			until = s.Until
+		}
+	}
+	return until
+}
+
+// removeSilence removes a silence. it must be called with e.mu held.
+func (e *Engine) removeSilence(id string) bool {
+	for i, s := range e.silences {
+		if s.ID == id {
+			e.silences = append(e.silences[:i], e.silences[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// pruneSilences drops the silences that ended before now. it must be
+// called with e.mu held.
+func (e *Engine) pruneSilences(now time.Time) {
+	active := e.silences[:0]
+	for _, s := range e.silences {
+		if s.Until.After(now) {
+			active = append(active, s)
+		}
+	}
+	e.silences = active
+}
+
+// writeJSON writes v as the JSON body of a response.
+func writeJSON(w http.ResponseWriter, statusCode int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,50 @@
+package allgood
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/saintmalik/allgood/notify"
+)
+
+func TestSilenceDropsFailuresButNotRecoveries(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	e := NewEngine(testCheck("db", func(context.Context) (bool, string) {
+		if healthy.Load() {
+			return true, "ok"
+		}
+		return false, "down"
+	}))
+	notifications := &recorder{}
+	e.SetNotifier(notifications)
+	run := func() {
+		t.Helper()
+		e.collectResults(context.Background(), true, nil)
+		if err := e.FlushNotifications(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run()
+	healthy.Store(false)
+	run()
+	if _, err := e.AddSilence(Silence{Checks: []string{"test/db"}, Until: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	healthy.Store(true)
+	run()
+	healthy.Store(false)
+	run()
+
+	var statuses []string
+	for _, n := range notifications.sent() {
+		statuses = append(statuses, n.Status)
+	}
+	want := []string{notify.StatusFail, notify.StatusPass}
+	if len(statuses) != len(want) || statuses[0] != want[0] || statuses[1] != want[1] {
+		t.Fatalf("notified %v, want %v", statuses, want)
+	}
+}
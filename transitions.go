@@ -0,0 +1,77 @@
+package allgood
+
+import (
+	"time"
+
+	"github.com/saintmalik/allgood/internal/models"
+	"github.com/saintmalik/allgood/notify"
+)
+
+// transition is a check moving from one status to another.
+type transition struct {
+	check    CheckConfig
+	from     models.Status
+	to       models.Status
+	duration time.Duration
+	result   models.Result
+}
+
+// detectTransition reports whether result moves the check to another
+// status than its previous result. a check seen for the first time is
+// considered healthy before.
+//
+// entering a maintenance window isn't a transition, a check leaving one
+// is compared with its status before the window.
+func detectTransition(check CheckConfig, previous models.Result, seen bool, result models.Result) (transition, bool) {
+	from := models.StatusPass
+	var duration time.Duration
+	if seen {
+		from = previous.Status
+		duration = result.FinishedAt.Sub(previous.StatusSince)
+	}
+	if from == models.StatusMaintenance {
+		from = checkStatus(check, previous.Success)
+	}
+	if from == result.Status || result.Status == models.StatusMaintenance {
+		return transition{}, false
+	}
+	return transition{
+		check:    check,
+		from:     from,
+		to:       result.Status,
+		duration: duration,
+		result:   result,
+	}, true
+}
+
+// notification builds the notification of the transition.
+func (t transition) notification(service, overall string, failing []string) notify.Notification {
+	return notify.Notification{
+		Service:                service,
+		CheckID:                t.check.Id.String(),
+		CheckKey:               t.check.Key,
+		CheckName:              t.check.Name,
+		CheckType:              string(t.check.Type),
+		Criticality:            string(t.check.criticality()),
+		Tags:                   t.check.Tags,
+		PreviousStatus:         string(t.from),
+		Status:                 string(t.to),
+		PreviousStatusDuration: t.duration,
+		Message:                t.result.Message,
+		StartedAt:              t.result.StartedAt,
+		FinishedAt:             t.result.FinishedAt,
+		Duration:               t.result.Duration,
+		OverallStatus:          overall,
+		FailingChecks:          failing,
+	}
+}
+
+// dispatch queues the notification of the transition to every notifier
+// routed the check.
+func (t transition) dispatch(notifiers []*routedNotifier, n notify.Notification) {
+	for _, notifier := range notifiers {
+		if notifier.matches(t.check) {
+			notifier.enqueue(n)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package allgood
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/saintmalik/allgood/internal/models"
+	"github.com/saintmalik/allgood/notify"
+)
+
+func TestTransitionsNotifyOncePerChange(t *testing.T) {
+	var healthy atomic.Bool
+	e := NewEngine(
+		testCheck("db", func(context.Context) (bool, string) {
+			if healthy.Load() {
+				return true, "ok"
+			}
+			return false, "down"
+		}),
+		func() CheckConfig {
+			config := testCheck("cache", passing)()
+			config.Criticality = CriticalityWarning
+			return config
+		},
+	)
+	notifications := &recorder{}
+	e.SetName("payments-api")
+	e.SetNotifier(notifications)
+
+	for _, state := range []bool{false, false, true, true, false} {
+		healthy.Store(state)
+		e.collectResults(context.Background(), true, nil)
+	}
+	if err := e.FlushNotifications(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := notifications.sent()
+	want := []struct{ from, to string }{
+		{notify.StatusPass, notify.StatusFail},
+		{notify.StatusFail, notify.StatusPass},
+		{notify.StatusPass, notify.StatusFail},
+	}
+	if len(sent) != len(want) {
+		t.Fatalf("sent %d notifications, want %d: %v", len(sent), len(want), sent)
+	}
+	for i, n := range sent {
+		if n.PreviousStatus != want[i].from || n.Status != want[i].to {
+			t.Errorf("notification %d moved from %s to %s, want %s to %s", i, n.PreviousStatus, n.Status, want[i].from, want[i].to)
+		}
+		if n.Service != "payments-api" || n.CheckKey != "test/db" {
+			t.Errorf("notification %d is of %s %s", i, n.Service, n.CheckKey)
+		}
+	}
+	if sent[0].PreviousStatusDuration != 0 || sent[1].PreviousStatusDuration <= 0 {
+		t.Errorf("previous status durations = %s and %s, want none for the first run", sent[0].PreviousStatusDuration, sent[1].PreviousStatusDuration)
+	}
+	if sent[0].OverallStatus != StatusError || len(sent[0].FailingChecks) != 1 || sent[0].FailingChecks[0] != "db" {
+		t.Errorf("first notification reports %s with %v failing", sent[0].OverallStatus, sent[0].FailingChecks)
+	}
+}
+
+func TestWarningChecksNotifyDegraded(t *testing.T) {
+	e := NewEngine(func() CheckConfig {
+		config := testCheck("disk", func(context.Context) (bool, string) {
+			return false, "90% used"
+		})()
+		config.Criticality = CriticalityWarning
+		return config
+	})
+	notifications := &recorder{}
+	e.SetNotifier(notifications)
+
+	e.collectResults(context.Background(), true, nil)
+	if err := e.FlushNotifications(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	sent := notifications.sent()
+	if len(sent) != 1 || sent[0].Status != notify.StatusWarn || sent[0].OverallStatus != StatusDegraded {
+		t.Fatalf("notifications = %v, want the check degraded", sent)
+	}
+}
+
+func TestStatusFuncWarnings(t *testing.T) {
+	var status atomic.Value
+	status.Store(CheckWarn)
+	var calls atomic.Int32
+	e := NewEngine(func() CheckConfig {
+		return CheckConfig{
+			Type:    "test",
+			Name:    "disk",
+			Enabled: true,
+			Retry:   RetryPolicy{Attempts: 3},
+			StatusFunc: func(context.Context) (CheckStatus, string) {
+				calls.Add(1)
+				return status.Load().(CheckStatus), "85% used"
+			},
+		}
+	})
+	notifications := &recorder{}
+	e.SetNotifier(notifications)
+
+	tests := []struct {
+		status   CheckStatus
+		want     models.Status
+		overall  string
+		attempts int
+	}{
+		{status: CheckWarn, want: models.StatusWarn, overall: StatusDegraded, attempts: 1},
+		{status: CheckPass, want: models.StatusPass, overall: StatusOK, attempts: 1},
+		{status: "broken", want: models.StatusFail, overall: StatusError, attempts: 3},
+	}
+	for _, tt := range tests {
+		status.Store(tt.status)
+		calls.Store(0)
+		results, _ := e.collectResults(context.Background(), true, nil)
+		if got := results[0]; got.Status != tt.want || got.Success != (tt.want != models.StatusFail) || got.Attempts != tt.attempts {
+			t.Errorf("%s: result = %s, success %t after %d attempts, want %s after %d", tt.status, got.Status, got.Success, got.Attempts, tt.want, tt.attempts)
+		}
+		if overall := e.CachedStatus(); overall != tt.overall {
+			t.Errorf("%s: overall status = %s, want %s", tt.status, overall, tt.overall)
+		}
+	}
+
+	if err := e.FlushNotifications(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var moves []string
+	for _, n := range notifications.sent() {
+		moves = append(moves, n.PreviousStatus+">"+n.Status)
+	}
+	if want := []string{"pass>warn", "warn>pass", "pass>fail"}; !reflect.DeepEqual(moves, want) {
+		t.Errorf("transitions = %v, want %v", moves, want)
+	}
+}
@@ -0,0 +1,74 @@
+package allgood
+
+import "context"
+
+// Status returns the overall status of the enabled checks matching any
+// of routes, or of every check when no route is given: one of StatusOK,
+// StatusDegraded or StatusError. checks without a result yet are run
+// first, like for HealthCheckHandler.
+func (e *Engine) Status(ctx context.Context, routes ...Route) string {
+	results, _ := e.collectResults(ctx, false, routesFilter(routes))
+	status, _ := overallStatus(results)
+	return status
+}
+
+// CachedStatus is like Status but never runs checks, the checks without
+// a result yet are left out.
+func (e *Engine) CachedStatus(routes ...Route) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	status, _ := e.cachedStatus(routesFilter(routes))
+	return status
+}
+
+// Watch subscribes to the runs of the checks, updates receives a value
+// after checks ran unless one is already pending. stop must be called
+// once the updates aren't needed anymore.
+//
+// running checks from the receiving goroutine, e.g. through Status,
+// makes it receive an update for its own run, use CachedStatus there.
+func (e *Engine) Watch() (updates <-chan struct{}, stop func()) {
+	ch := make(chan struct{}, 1)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.watchers == nil {
+		e.watchers = make(map[chan struct{}]struct{})
+	}
+	e.watchers[ch] = struct{}{}
+	return ch, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		delete(e.watchers, ch)
+	}
+}
+
+// notifyWatchers tells the watchers checks ran. it must be called with
+// e.mu held.
+func (e *Engine) notifyWatchers() {
+	for ch := range e.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// routesFilter builds a checkFilter matching the checks matching any of
+// routes, nil when there are none.
+func routesFilter(routes []Route) checkFilter {
+	if len(routes) == 0 {
+		return nil
+	}
+	filters := make([]checkFilter, len(routes))
+	for i, route := range routes {
+		filters[i] = route.filter()
+	}
+	return func(check CheckConfig) bool {
+		for _, filter := range filters {
+			if filter(check) {
+				return true
+			}
+		}
+		return false
+	}
+}